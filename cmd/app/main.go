@@ -3,15 +3,27 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"log"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/turbak/bigmacindex/internal/app"
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/index"
+	"github.com/turbak/bigmacindex/internal/poller"
+	"github.com/turbak/bigmacindex/internal/storage/countries"
+	"github.com/turbak/bigmacindex/internal/storage/currencies"
+	"github.com/turbak/bigmacindex/internal/storage/fxrates"
 	"github.com/turbak/bigmacindex/internal/storage/links"
+	"github.com/turbak/bigmacindex/internal/storage/pollruns"
 	"github.com/turbak/bigmacindex/internal/storage/prices"
 )
 
 func main() {
+	archiveDir := flag.String("archive-dir", "./archive", "directory the poller archives responses to")
+	flag.Parse()
+
 	ctx := context.Background()
 
 	db, err := sql.Open("sqlite3", "./bigmacindex.db")
@@ -22,10 +34,25 @@ func main() {
 
 	linksRepo := links.NewRepository(db)
 	pricesRepo := prices.NewRepository(db)
-
-	linksRoutes := app.NewLinksRoutes(linksRepo)
-
-	pricesApp := app.NewApp(linksRoutes, pricesRepo)
+	countriesRepo := countries.NewRepository(db)
+	fxRatesRepo := fxrates.NewRepository(db)
+	pollRunsRepo := pollruns.NewRepository(db)
+	currenciesRepo := currencies.NewRepository(db)
+	indexRepo := index.NewRepository(pricesRepo, fxRatesRepo)
+
+	// Previews and selector suggestions answer an interactive request, so they neither retry nor trip circuit breakers.
+	previewCfg := poller.DefaultConfig()
+	previewCfg.RequestTimeout = 15 * time.Second
+	previewCfg.MinIntervalPerHost = 0
+	previewCfg.Retry.MaxAttempts = 1
+	previewCfg.Breaker.FailureThreshold = 0
+	previewer := poller.NewPoller(linksRepo, pricesRepo, currenciesRepo, previewCfg)
+
+	linksRoutes := app.NewLinksRoutes(linksRepo, previewer)
+	indexRoutes := app.NewIndexRoutes(indexRepo, countriesRepo)
+	pollRunsRoutes := app.NewPollRunsRoutes(pollRunsRepo, archive.NewStore(*archiveDir))
+
+	pricesApp := app.NewApp(linksRoutes, indexRoutes, pollRunsRoutes, pricesRepo, countriesRepo)
 
 	if err := pricesApp.SetupRoutes(ctx); err != nil {
 		log.Fatalf("failed to set up routes: %v", err)
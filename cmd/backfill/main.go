@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/turbak/bigmacindex/internal/backfill"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller"
+	"github.com/turbak/bigmacindex/internal/storage/currencies"
+	"github.com/turbak/bigmacindex/internal/storage/links"
+	"github.com/turbak/bigmacindex/internal/storage/prices"
+)
+
+func main() {
+	linkID := flag.Int("link", 0, "ID of the link whose selector and country the snapshots are parsed with")
+	captured := flag.String("captured", "", "capture date (YYYY-MM-DD) of snapshot files whose names carry no date or Wayback timestamp")
+	anyURL := flag.Bool("any-url", false, "import every response of WARC files, not only those of the link's URL")
+	dryRun := flag.Bool("dry-run", false, "report the prices that would be saved without saving them")
+	flag.Parse()
+
+	if *linkID == 0 || flag.NArg() == 0 {
+		log.Fatalf("usage: backfill -link ID [-captured YYYY-MM-DD] [-any-url] [-dry-run] FILE...")
+	}
+
+	opts := backfill.Options{AnyURL: *anyURL, DryRun: *dryRun}
+	if *captured != "" {
+		capturedAt, err := time.Parse(time.DateOnly, *captured)
+		if err != nil {
+			log.Fatalf("invalid capture date: %v", err)
+		}
+		opts.CapturedAt = capturedAt
+	}
+
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", "./bigmacindex.db")
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	linksRepo := links.NewRepository(db)
+	pricesRepo := prices.NewRepository(db)
+	currenciesRepo := currencies.NewRepository(db)
+
+	linkDesc, err := linksRepo.GetLinkByID(ctx, link.ID(*linkID))
+	if err != nil {
+		log.Fatalf("failed to get link #%d: %v", *linkID, err)
+	}
+
+	pricePoller := poller.NewPoller(linksRepo, pricesRepo, currenciesRepo, poller.DefaultConfig())
+	importer := backfill.NewImporter(pricePoller, pricesRepo)
+
+	report, err := importer.ImportFiles(ctx, linkDesc, flag.Args(), opts)
+	if err != nil {
+		log.Fatalf("backfill failed: %v", err)
+	}
+
+	for _, failure := range report.Failures {
+		log.Printf("No price in %s (captured %s): %v", failure.Ref, failure.CapturedAt.Format(time.DateTime), failure.Err)
+	}
+	for _, priceRec := range report.Kept {
+		log.Printf("Kept the polled price of %s, backfill read %s", priceRec.CreatedDate, priceRec.Price)
+	}
+	verb, saved := "Backfilled", "saved"
+	if *dryRun {
+		verb, saved = "Would backfill", "to save"
+	}
+	for _, priceRec := range report.Inserted {
+		log.Printf("%s %s in %s on %s from %s", verb, priceRec.Price, priceRec.CountryCode, priceRec.CreatedDate, priceRec.SourceRef)
+	}
+
+	log.Printf("Backfill of link #%d (%s, %s) completed: %d prices %s, %d days kept, %d snapshots without a price, %d responses of other URLs skipped",
+		linkDesc.ID, linkDesc.ProductName, linkDesc.CountryCode, len(report.Inserted), saved, len(report.Kept), len(report.Failures), report.Skipped)
+}
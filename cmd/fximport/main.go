@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/turbak/bigmacindex/internal/fximport"
+	"github.com/turbak/bigmacindex/internal/storage/fxrates"
+)
+
+func main() {
+	format := flag.String("format", "", "input format: ecb-xml, ecb-csv or csv (inferred from the file name when empty)")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatalf("usage: fximport [-format ecb-xml|ecb-csv|csv] FILE...")
+	}
+
+	ctx := context.Background()
+
+	db, err := sql.Open("sqlite3", "./bigmacindex.db")
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	fxRatesRepo := fxrates.NewRepository(db)
+	importer := fximport.NewFXImporter(fxRatesRepo)
+
+	for _, path := range flag.Args() {
+		imported, err := importer.ImportFile(ctx, path, fximport.Format(*format))
+		if err != nil {
+			log.Fatalf("failed to import %s: %v", path, err)
+		}
+
+		log.Printf("Imported %d rates from %s", imported, path)
+	}
+}
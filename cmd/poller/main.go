@@ -3,16 +3,61 @@ package main
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
 	"github.com/turbak/bigmacindex/internal/poller"
+	"github.com/turbak/bigmacindex/internal/scheduler"
+	"github.com/turbak/bigmacindex/internal/storage/currencies"
 	"github.com/turbak/bigmacindex/internal/storage/links"
+	"github.com/turbak/bigmacindex/internal/storage/pollruns"
 	"github.com/turbak/bigmacindex/internal/storage/prices"
 )
 
+type linksPoller interface {
+	PollLinks(ctx context.Context, links []link.LinkDescription) (poller.Result, error)
+}
+
+type pollRunSaver interface {
+	SaveRun(ctx context.Context, run pollrun.Run) (pollrun.Run, error)
+}
+
 func main() {
-	ctx := context.Background()
+	cfg := poller.DefaultConfig()
+	flag.IntVar(&cfg.Workers, "workers", cfg.Workers, "number of links polled concurrently")
+	flag.IntVar(&cfg.MaxConcurrentPerHost, "per-host", cfg.MaxConcurrentPerHost, "maximum concurrent requests to a single host")
+	flag.DurationVar(&cfg.MinIntervalPerHost, "per-host-interval", cfg.MinIntervalPerHost, "minimum delay between requests to a single host")
+	flag.DurationVar(&cfg.RequestTimeout, "timeout", cfg.RequestTimeout, "HTTP request timeout of links without their own")
+	flag.IntVar(&cfg.Retry.MaxAttempts, "retry-attempts", cfg.Retry.MaxAttempts, "attempts per link for network errors and retryable statuses")
+	flag.DurationVar(&cfg.Retry.BaseDelay, "retry-base-delay", cfg.Retry.BaseDelay, "initial retry backoff")
+	flag.DurationVar(&cfg.Retry.MaxDelay, "retry-max-delay", cfg.Retry.MaxDelay, "maximum retry backoff and longest honored Retry-After")
+	flag.IntVar(&cfg.Breaker.FailureThreshold, "breaker-threshold", cfg.Breaker.FailureThreshold, "consecutive failures that open a link or host circuit (0 disables)")
+	flag.DurationVar(&cfg.Breaker.Cooldown, "breaker-cooldown", cfg.Breaker.Cooldown, "how long an open circuit stays open")
+
+	archiveDir := flag.String("archive-dir", "./archive", "directory responses are archived to as WARC files (empty disables archiving)")
+	archiveRetention := flag.Duration("archive-retention", 90*24*time.Hour, "how long archived responses are kept (0 keeps them forever)")
+
+	replay := flag.Bool("replay", false, "parse the archived responses of past polls again with the current links instead of polling, and report the prices that change")
+	replaySince := flag.Duration("replay-since", 30*24*time.Hour, "how far back to replay archived responses (replay mode)")
+	replayLink := flag.Int("replay-link", 0, "only replay the responses of this link ID (replay mode)")
+	confirm := flag.Bool("confirm", false, "save the prices that change on replay (replay mode)")
+
+	daemon := flag.Bool("daemon", false, "keep running and poll on a schedule instead of once")
+	schedule := flag.String("schedule", "0 6 * * *", "cron schedule for links without their own schedule (daemon mode)")
+	jitter := flag.Duration("jitter", 5*time.Minute, "random delay added to every scheduled run (daemon mode)")
+	shutdownTimeout := flag.Duration("shutdown-timeout", time.Minute, "how long an in-flight run may finish after SIGTERM (daemon mode)")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	db, err := sql.Open("sqlite3", "./bigmacindex.db")
 	if err != nil {
@@ -22,11 +67,112 @@ func main() {
 
 	linksRepo := links.NewRepository(db)
 	pricesRepo := prices.NewRepository(db)
+	currenciesRepo := currencies.NewRepository(db)
+	pollRunsRepo := pollruns.NewRepository(db)
+	cfg.History = pollRunsRepo
+
+	var archiveStore *archive.Store
+	if *archiveDir != "" {
+		archiveStore = archive.NewStore(*archiveDir)
+		cfg.Archiver = archiveStore
+	}
 
-	pricePoller := poller.NewPoller(linksRepo, pricesRepo)
-	if err := pricePoller.Poll(ctx); err != nil {
-		log.Fatalf("failed to poll prices: %v", err)
+	pricePoller := poller.NewPoller(linksRepo, pricesRepo, currenciesRepo, cfg)
+
+	if *replay {
+		if archiveStore == nil {
+			log.Fatalf("replay needs the archived responses of -archive-dir")
+		}
+
+		r := replayer{
+			poller:   pricePoller,
+			attempts: pollRunsRepo,
+			links:    linksRepo,
+			archive:  archiveStore,
+			prices:   pricesRepo,
+			out:      os.Stdout,
+		}
+		err := r.run(ctx, replayOptions{
+			Since:   time.Now().Add(-*replaySince),
+			LinkID:  link.ID(*replayLink),
+			Confirm: *confirm,
+		})
+		if err != nil {
+			log.Fatalf("replay failed: %v", err)
+		}
+		return
+	}
+
+	if *daemon {
+		globalSchedule, err := scheduler.Parse(*schedule)
+		if err != nil {
+			log.Fatalf("invalid schedule: %v", err)
+		}
+
+		sched := scheduler.NewScheduler(linksRepo, func(ctx context.Context, links []link.LinkDescription) {
+			pruneArchive(archiveStore, *archiveRetention)
+			pollAndSave(ctx, pricePoller, pollRunsRepo, links)
+		}, scheduler.Config{
+			Schedule:        globalSchedule,
+			Jitter:          *jitter,
+			ShutdownTimeout: *shutdownTimeout,
+			RetryInterval:   time.Minute,
+		})
+
+		log.Printf("Starting poller daemon with schedule %q", *schedule)
+		if err := sched.Run(ctx); err != nil {
+			log.Fatalf("poller daemon stopped uncleanly: %v", err)
+		}
+		log.Println("Poller daemon stopped")
+		return
+	}
+
+	linkDescs, err := linksRepo.ListLinks(ctx)
+	if err != nil {
+		log.Fatalf("failed to list links: %v", err)
 	}
 
-	log.Println("Price polling completed successfully")
+	pruneArchive(archiveStore, *archiveRetention)
+	if failed := pollAndSave(ctx, pricePoller, pollRunsRepo, linkDescs); failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// pollAndSave polls links, persists the run and logs every failure. It returns the number of failed links.
+func pollAndSave(ctx context.Context, pricePoller linksPoller, pollRunsRepo pollRunSaver, linkDescs []link.LinkDescription) int {
+	result, err := pricePoller.PollLinks(ctx, linkDescs)
+	if err != nil {
+		log.Printf("polling was interrupted: %v", err)
+	}
+
+	run, err := pollRunsRepo.SaveRun(context.WithoutCancel(ctx), result.PollRun())
+	if err != nil {
+		log.Printf("failed to save poll run: %v", err)
+	} else {
+		log.Printf("Saved poll run #%d", run.ID)
+	}
+
+	failures := result.Failures()
+	for _, outcome := range failures {
+		log.Printf("Failed to poll link #%d (%s, %s): %v", outcome.Link.ID, outcome.Link.ProductName, outcome.Link.CountryCode, outcome.Err)
+	}
+
+	log.Printf("Price polling completed: %d succeeded, %d failed", len(result.Successes()), len(failures))
+	return len(failures)
+}
+
+// pruneArchive deletes the archived responses older than retention. Archiving and retention are off when store is nil or retention is 0.
+func pruneArchive(store *archive.Store, retention time.Duration) {
+	if store == nil || retention <= 0 {
+		return
+	}
+
+	pruned, err := store.Prune(time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("failed to prune archived responses: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("Pruned %d archived responses older than %s", pruned, retention)
+	}
 }
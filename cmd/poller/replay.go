@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"text/tabwriter"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+	"github.com/turbak/bigmacindex/internal/poller"
+)
+
+type snapshotReplayer interface {
+	Replay(ctx context.Context, snapshots []poller.Snapshot) ([]poller.LinkOutcome, error)
+}
+
+type archivedAttemptsLister interface {
+	ListArchivedAttempts(ctx context.Context, since time.Time) ([]pollrun.Attempt, error)
+}
+
+type linksLister interface {
+	ListLinks(ctx context.Context) ([]link.LinkDescription, error)
+}
+
+type responseGetter interface {
+	Get(key string) (archive.Record, error)
+}
+
+type pricesStore interface {
+	ListPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error)
+	UpsertPrice(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, error)
+}
+
+type replayOptions struct {
+	Since  time.Time
+	LinkID link.ID
+	// Confirm saves the replayed prices that differ from the stored ones.
+	Confirm bool
+}
+
+// replayer re-parses archived responses of past poll attempts with the current link configuration
+// and reports how the extracted prices change.
+type replayer struct {
+	poller   snapshotReplayer
+	attempts archivedAttemptsLister
+	links    linksLister
+	archive  responseGetter
+	prices   pricesStore
+	out      io.Writer
+}
+
+// replayed pairs a past attempt with the outcome of parsing its archived response again.
+type replayed struct {
+	attempt pollrun.Attempt
+	outcome poller.LinkOutcome
+}
+
+// change classifies how a replayed attempt differs from the original one.
+func (r replayed) change() string {
+	switch {
+	case r.attempt.Failed() && r.outcome.Err != nil:
+		return "still failing"
+	case r.attempt.Failed():
+		return "fixed"
+	case r.outcome.Err != nil:
+		return "broken"
+	case r.attempt.ParsedPrice != r.newPrice():
+		return "changed"
+	default:
+		return "unchanged"
+	}
+}
+
+func (r replayed) oldPrice() string {
+	if r.attempt.Failed() {
+		return r.attempt.ErrorKind
+	}
+	return r.attempt.ParsedPrice
+}
+
+func (r replayed) newPrice() string {
+	if r.outcome.Err != nil {
+		return string(r.outcome.Err.Kind)
+	}
+	return r.outcome.Price.Price.String()
+}
+
+func (r replayer) run(ctx context.Context, opts replayOptions) error {
+	attempts, err := r.attempts.ListArchivedAttempts(ctx, opts.Since)
+	if err != nil {
+		return fmt.Errorf("failed to list archived attempts: %w", err)
+	}
+
+	linkDescs, err := r.links.ListLinks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list links: %w", err)
+	}
+	linksByID := make(map[link.ID]link.LinkDescription, len(linkDescs))
+	for _, linkDesc := range linkDescs {
+		linksByID[linkDesc.ID] = linkDesc
+	}
+
+	var (
+		replayedAttempts []pollrun.Attempt
+		snapshots        []poller.Snapshot
+		skipped          int
+	)
+	for _, attempt := range attempts {
+		if opts.LinkID != 0 && attempt.LinkID != opts.LinkID {
+			continue
+		}
+		linkDesc, ok := linksByID[attempt.LinkID]
+		if !ok {
+			skipped++
+			continue
+		}
+
+		resp, err := r.archive.Get(attempt.ResponseKey)
+		if errors.Is(err, archive.ErrNotFound) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archived response of attempt #%d: %w", attempt.ID, err)
+		}
+
+		replayedAttempts = append(replayedAttempts, attempt)
+		snapshots = append(snapshots, poller.Snapshot{Link: linkDesc, Response: resp, PolledAt: attempt.CreatedAt})
+	}
+
+	outcomes, err := r.poller.Replay(ctx, snapshots)
+	if err != nil {
+		return err
+	}
+
+	results := make([]replayed, len(outcomes))
+	for i, outcome := range outcomes {
+		results[i] = replayed{attempt: replayedAttempts[i], outcome: outcome}
+	}
+
+	r.report(results, skipped)
+
+	stored, err := r.prices.ListPrices(ctx, price.Filter{From: opts.Since.Format(time.DateOnly)})
+	if err != nil {
+		return fmt.Errorf("failed to list prices: %w", err)
+	}
+	updates := priceUpdates(results, stored)
+	if !opts.Confirm {
+		fmt.Fprintf(r.out, "%d prices would be updated; run again with -confirm to save them.\n", len(updates))
+		return nil
+	}
+
+	for _, priceRec := range updates {
+		if _, err := r.prices.UpsertPrice(ctx, priceRec); err != nil {
+			return fmt.Errorf("failed to save price of %s in %s on %s: %w", priceRec.ProductName, priceRec.CountryCode, priceRec.CreatedDate, err)
+		}
+	}
+	fmt.Fprintf(r.out, "Saved %d prices.\n", len(updates))
+	return nil
+}
+
+// report prints every replayed attempt whose result changed, followed by a summary.
+func (r replayer) report(results []replayed, skipped int) {
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ATTEMPT\tPOLLED\tLINK\tPRODUCT\tCOUNTRY\tOLD\tNEW\tCHANGE")
+	for _, res := range results {
+		change := res.change()
+		counts[change]++
+		if change == "unchanged" || change == "still failing" {
+			continue
+		}
+
+		a := res.attempt
+		fmt.Fprintf(tw, "#%d\t%s\t#%d\t%s\t%s\t%s\t%s\t%s\n", a.ID, a.CreatedAt.Format(time.DateTime), a.LinkID, a.ProductName, a.CountryCode,
+			res.oldPrice(), res.newPrice(), change)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(r.out, "\nReplayed %d archived responses: %d unchanged, %d changed, %d fixed, %d broken, %d still failing.\n",
+		len(results), counts["unchanged"], counts["changed"], counts["fixed"], counts["broken"], counts["still failing"])
+	if skipped > 0 {
+		fmt.Fprintf(r.out, "Skipped %d attempts whose link was deleted or whose response was pruned.\n", skipped)
+	}
+	for _, res := range results {
+		if res.change() == "broken" {
+			log.Printf("Attempt #%d of link #%d no longer parses: %v", res.attempt.ID, res.attempt.LinkID, res.outcome.Err)
+		}
+	}
+}
+
+// priceUpdates returns the replayed prices that differ from the stored ones. As when polling,
+// the last attempt of a day sets the price of that day.
+func priceUpdates(results []replayed, stored []price.PriceRecord) []price.PriceRecord {
+	type priceKey struct {
+		productName, countryCode, date string
+	}
+
+	storedPrices := make(map[priceKey]string, len(stored))
+	for _, priceRec := range stored {
+		storedPrices[priceKey{priceRec.ProductName, priceRec.CountryCode, priceRec.CreatedDate}] = priceRec.Price.String()
+	}
+
+	var (
+		keys   []priceKey
+		latest = make(map[priceKey]price.PriceRecord)
+	)
+	for _, res := range results {
+		if res.outcome.Err != nil {
+			continue
+		}
+		priceRec := res.outcome.Price
+		key := priceKey{priceRec.ProductName, priceRec.CountryCode, priceRec.CreatedDate}
+		if _, ok := latest[key]; !ok {
+			keys = append(keys, key)
+		}
+		latest[key] = priceRec
+	}
+
+	var updates []price.PriceRecord
+	for _, key := range keys {
+		if priceRec := latest[key]; priceRec.Price.String() != storedPrices[key] {
+			updates = append(updates, priceRec)
+		}
+	}
+	return updates
+}
@@ -2,16 +2,19 @@ module github.com/turbak/bigmacindex
 
 go 1.25.4
 
-require golang.org/x/net v0.48.0
+require (
+	github.com/Masterminds/squirrel v1.5.4
+	github.com/andybalholm/cascadia v1.3.3
+	github.com/antchfx/htmlquery v1.3.5
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/oliveagle/jsonpath v0.0.0-20180606110733-2e52cf6e6852
+	golang.org/x/net v0.48.0
+)
 
 require (
-	github.com/Masterminds/squirrel v1.5.4 // indirect
-	github.com/antchfx/htmlquery v1.3.5 // indirect
 	github.com/antchfx/xpath v1.3.5 // indirect
 	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
 	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.33 // indirect
-	github.com/oliveagle/jsonpath v0.0.0-20180606110733-2e52cf6e6852 // indirect
 	golang.org/x/text v0.32.0 // indirect
 )
@@ -3,10 +3,13 @@ package app
 import (
 	"context"
 	"embed"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/turbak/bigmacindex/internal/domain/country"
 	"github.com/turbak/bigmacindex/internal/domain/price"
 )
 
@@ -14,21 +17,31 @@ import (
 var templates embed.FS
 
 type PriceLister interface {
-	ListPrices(ctx context.Context) ([]price.PriceRecord, error)
+	ListPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error)
+	ListLatestPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error)
 }
 
 type App struct {
-	linksRoutes *LinksRoutes
-	priceRepo   PriceLister
+	linksRoutes    *LinksRoutes
+	indexRoutes    *IndexRoutes
+	pollRunsRoutes *PollRunsRoutes
+	priceRepo      PriceLister
+	countriesRepo  CountriesLister
 }
 
 func NewApp(
 	linksRoutes *LinksRoutes,
+	indexRoutes *IndexRoutes,
+	pollRunsRoutes *PollRunsRoutes,
 	priceRepo PriceLister,
+	countriesRepo CountriesLister,
 ) *App {
 	return &App{
-		linksRoutes: linksRoutes,
-		priceRepo:   priceRepo,
+		linksRoutes:    linksRoutes,
+		indexRoutes:    indexRoutes,
+		pollRunsRoutes: pollRunsRoutes,
+		priceRepo:      priceRepo,
+		countriesRepo:  countriesRepo,
 	}
 }
 
@@ -37,17 +50,126 @@ func (a *App) SetupRoutes(_ context.Context) error {
 
 	mux.HandleFunc("GET /links", a.linksRoutes.GetLinks())
 	mux.HandleFunc("POST /links", a.linksRoutes.CreateLink())
+	mux.HandleFunc("POST /links/preview", a.linksRoutes.PreviewLink())
+	mux.HandleFunc("POST /links/suggest", a.linksRoutes.SuggestLinkSelectors())
 	mux.HandleFunc("DELETE /links/{id}", a.linksRoutes.DeleteLink)
 	mux.HandleFunc("GET /links/{id}/edit", a.linksRoutes.EditLink())
 	mux.HandleFunc("PUT /links/{id}", a.linksRoutes.UpdateLink())
 	mux.HandleFunc("GET /links/{id}", a.linksRoutes.GetLink())
 
-	mux.HandleFunc("GET /prices", a.GetPrices)
+	mux.HandleFunc("GET /prices", a.GetPrices())
+	mux.HandleFunc("GET /prices/{country}", a.GetPriceHistory())
+
+	mux.HandleFunc("GET /index", a.indexRoutes.GetIndex())
+
+	mux.HandleFunc("GET /poll-runs", a.pollRunsRoutes.GetPollRuns())
+	mux.HandleFunc("GET /poll-runs/{id}", a.pollRunsRoutes.GetPollRun())
+	mux.HandleFunc("GET /archive/{key}", a.pollRunsRoutes.GetArchivedResponse)
+	mux.HandleFunc("GET /archive/{key}/warc", a.pollRunsRoutes.DownloadArchivedResponse)
 
 	return http.ListenAndServe(":8080", mux)
 }
 
-func (a *App) GetPrices(rw http.ResponseWriter, req *http.Request) {
+func (a *App) GetPrices() func(rw http.ResponseWriter, req *http.Request) {
+	templ := template.Must(template.ParseFS(templates, "templates/prices.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		filter, err := parsePriceFilter(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
+			return
+		}
+
+		countries, err := a.countriesRepo.ListCountries(ctx)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to list countries: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		prices, err := a.priceRepo.ListLatestPrices(ctx, filter)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to list prices: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			Filter    price.Filter
+			Countries []country.Country
+			Prices    []price.PriceRecord
+		}{
+			Filter:    filter,
+			Countries: countries,
+			Prices:    prices,
+		}
+
+		err = templ.Execute(rw, data)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render prices: %w", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (a *App) GetPriceHistory() func(rw http.ResponseWriter, req *http.Request) {
+	templ := template.Must(template.ParseFS(templates, "templates/prices.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		countryCode := req.PathValue("country")
+		if countryCode == "" {
+			renderError(rw, fmt.Errorf("missing country"), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := parsePriceFilter(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
+			return
+		}
+		filter.CountryCode = countryCode
+
+		prices, err := a.priceRepo.ListPrices(req.Context(), filter)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to list price history: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			CountryCode string
+			Prices      []price.PriceRecord
+		}{
+			CountryCode: countryCode,
+			Prices:      prices,
+		}
+
+		err = templ.ExecuteTemplate(rw, "price-history", data)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render price history: %w", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func parsePriceFilter(req *http.Request) (price.Filter, error) {
+	query := req.URL.Query()
+	filter := price.Filter{
+		CountryCode: query.Get("country"),
+		ProductName: query.Get("product"),
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+	}
+
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return price.Filter{}, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+	}
+
+	return filter, nil
 }
 
 var errorTempl = template.Must(template.ParseFS(templates, "templates/error-toast.html"))
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/country"
+	"github.com/turbak/bigmacindex/internal/index"
+)
+
+type IndexGetter interface {
+	GetIndex(ctx context.Context, date, baseCountryCode string) (index.Index, error)
+}
+
+type CountriesLister interface {
+	ListCountries(ctx context.Context) ([]country.Country, error)
+}
+
+type IndexRoutes struct {
+	indexRepo     IndexGetter
+	countriesRepo CountriesLister
+}
+
+func NewIndexRoutes(indexRepo IndexGetter, countriesRepo CountriesLister) *IndexRoutes {
+	return &IndexRoutes{
+		indexRepo:     indexRepo,
+		countriesRepo: countriesRepo,
+	}
+}
+
+func (a *IndexRoutes) GetIndex() func(rw http.ResponseWriter, req *http.Request) {
+	templ := template.Must(template.ParseFS(templates, "templates/index.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		date := req.URL.Query().Get("date")
+		if date == "" {
+			date = time.Now().Format(time.DateOnly)
+		}
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			renderError(rw, fmt.Errorf("invalid date %q: %w", date, err), http.StatusBadRequest)
+			return
+		}
+
+		baseCountryCode := req.URL.Query().Get("base")
+		if baseCountryCode == "" {
+			baseCountryCode = index.DefaultBaseCountryCode
+		}
+
+		countries, err := a.countriesRepo.ListCountries(ctx)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to list countries: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		// A missing base price is expected before the first poll, so it's shown inline rather than as a toast.
+		idx, indexErr := a.indexRepo.GetIndex(ctx, date, baseCountryCode)
+
+		data := struct {
+			Date            string
+			BaseCountryCode string
+			Countries       []country.Country
+			Index           index.Index
+			Error           error
+		}{
+			Date:            date,
+			BaseCountryCode: baseCountryCode,
+			Countries:       countries,
+			Index:           idx,
+			Error:           indexErr,
+		}
+
+		err = templ.Execute(rw, data)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render index: %w", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -2,12 +2,19 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller"
+	"github.com/turbak/bigmacindex/internal/scheduler"
+	"github.com/turbak/bigmacindex/internal/suggest"
 )
 
 type LinksCRUDer interface {
@@ -18,18 +25,25 @@ type LinksCRUDer interface {
 	GetLinkByID(ctx context.Context, linkID link.ID) (link.LinkDescription, error)
 }
 
+type LinkPreviewer interface {
+	Preview(ctx context.Context, linkDesc link.LinkDescription) (poller.LinkOutcome, error)
+	SuggestSelectors(ctx context.Context, linkDesc link.LinkDescription, seenPrice string) ([]suggest.Suggestion, error)
+}
+
 type LinksRoutes struct {
-	linkRepo LinksCRUDer
+	linkRepo  LinksCRUDer
+	previewer LinkPreviewer
 }
 
-func NewLinksRoutes(linkRepo LinksCRUDer) *LinksRoutes {
+func NewLinksRoutes(linkRepo LinksCRUDer, previewer LinkPreviewer) *LinksRoutes {
 	return &LinksRoutes{
-		linkRepo: linkRepo,
+		linkRepo:  linkRepo,
+		previewer: previewer,
 	}
 }
 
 func (a *LinksRoutes) GetLinks() func(rw http.ResponseWriter, req *http.Request) {
-	templ := template.Must(template.ParseFS(templates, "templates/links.html"))
+	templ := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
 
 	return func(rw http.ResponseWriter, req *http.Request) {
 		ctx := req.Context()
@@ -56,22 +70,15 @@ func (a *LinksRoutes) GetLinks() func(rw http.ResponseWriter, req *http.Request)
 }
 
 func (a *LinksRoutes) CreateLink() func(rw http.ResponseWriter, req *http.Request) {
-	tmpl := template.Must(template.ParseFS(templates, "templates/links.html"))
+	tmpl := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
 
 	return func(rw http.ResponseWriter, req *http.Request) {
-		if err := req.ParseForm(); err != nil {
-			renderError(rw, fmt.Errorf("failed to parse form: %w", err), http.StatusBadRequest)
+		newLink, err := linkFromForm(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
 			return
 		}
 
-		newLink := link.LinkDescription{
-			ProductName:   req.FormValue("product_name"),
-			URL:           req.FormValue("url"),
-			LinkType:      link.LinkType(req.FormValue("link_type")),
-			PriceSelector: req.FormValue("price_selector"),
-			CountryCode:   req.FormValue("country_code"),
-		}
-
 		createdLink, err := a.linkRepo.AddLink(req.Context(), newLink)
 		if err != nil {
 			renderError(rw, fmt.Errorf("failed to save link: %w", err), http.StatusInternalServerError)
@@ -85,6 +92,72 @@ func (a *LinksRoutes) CreateLink() func(rw http.ResponseWriter, req *http.Reques
 	}
 }
 
+// PreviewLink fetches the link submitted by the create or edit form and shows what the poller would extract, without saving it.
+func (a *LinksRoutes) PreviewLink() func(rw http.ResponseWriter, req *http.Request) {
+	tmpl := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		linkDesc, err := linkFromForm(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
+			return
+		}
+
+		outcome, err := a.previewer.Preview(req.Context(), linkDesc)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to preview link: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		err = tmpl.ExecuteTemplate(rw, "link-preview", outcome)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render preview: %w", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// SuggestLinkSelectors fetches the page of the link submitted by the create form and proposes
+// selectors reading the price the user sees on it.
+func (a *LinksRoutes) SuggestLinkSelectors() func(rw http.ResponseWriter, req *http.Request) {
+	tmpl := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		// The selector is what's being looked for, so only the rest of the link is read.
+		linkDesc, err := linkTargetFromForm(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
+			return
+		}
+
+		seenPrice := strings.TrimSpace(req.FormValue("seen_price"))
+		if seenPrice == "" {
+			renderError(rw, fmt.Errorf("enter the price shown on the page to get selector suggestions"), http.StatusBadRequest)
+			return
+		}
+
+		suggestions, err := a.previewer.SuggestSelectors(req.Context(), linkDesc, seenPrice)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to suggest selectors: %w", err), http.StatusBadGateway)
+			return
+		}
+
+		data := struct {
+			Link        link.LinkDescription
+			SeenPrice   string
+			Suggestions []suggest.Suggestion
+		}{
+			Link:        linkDesc,
+			SeenPrice:   seenPrice,
+			Suggestions: suggestions,
+		}
+
+		err = tmpl.ExecuteTemplate(rw, "link-suggestions", data)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render suggestions: %w", err), http.StatusInternalServerError)
+		}
+	}
+}
+
 func (a *LinksRoutes) DeleteLink(rw http.ResponseWriter, req *http.Request) {
 	idStr := req.PathValue("id")
 	if idStr == "" {
@@ -100,7 +173,7 @@ func (a *LinksRoutes) DeleteLink(rw http.ResponseWriter, req *http.Request) {
 
 	err = a.linkRepo.DeleteLink(req.Context(), link.ID(id))
 	if err != nil {
-		renderError(rw, fmt.Errorf("failed to delete: %w", err.Error()), http.StatusInternalServerError)
+		renderError(rw, fmt.Errorf("failed to delete: %w", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -108,7 +181,7 @@ func (a *LinksRoutes) DeleteLink(rw http.ResponseWriter, req *http.Request) {
 }
 
 func (a *LinksRoutes) UpdateLink() func(rw http.ResponseWriter, req *http.Request) {
-	templ := template.Must(template.ParseFS(templates, "templates/links.html"))
+	templ := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
 
 	return func(rw http.ResponseWriter, req *http.Request) {
 		idStr := req.PathValue("id")
@@ -123,19 +196,12 @@ func (a *LinksRoutes) UpdateLink() func(rw http.ResponseWriter, req *http.Reques
 			return
 		}
 
-		if err := req.ParseForm(); err != nil {
-			renderError(rw, fmt.Errorf("failed to parse form: %w", err), http.StatusBadRequest)
+		updatedLink, err := linkFromForm(req)
+		if err != nil {
+			renderError(rw, err, http.StatusBadRequest)
 			return
 		}
-
-		updatedLink := link.LinkDescription{
-			ID:            link.ID(id),
-			ProductName:   req.FormValue("product_name"),
-			URL:           req.FormValue("url"),
-			LinkType:      link.LinkType(req.FormValue("link_type")),
-			PriceSelector: req.FormValue("price_selector"),
-			CountryCode:   req.FormValue("country_code"),
-		}
+		updatedLink.ID = link.ID(id)
 
 		_, err = a.linkRepo.UpdateLink(req.Context(), updatedLink)
 		if err != nil {
@@ -152,7 +218,7 @@ func (a *LinksRoutes) UpdateLink() func(rw http.ResponseWriter, req *http.Reques
 }
 
 func (a *LinksRoutes) EditLink() func(rw http.ResponseWriter, req *http.Request) {
-	templ := template.Must(template.ParseFS(templates, "templates/links.html"))
+	templ := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
 
 	return func(rw http.ResponseWriter, req *http.Request) {
 		idStr := req.PathValue("id")
@@ -178,7 +244,7 @@ func (a *LinksRoutes) EditLink() func(rw http.ResponseWriter, req *http.Request)
 }
 
 func (a *LinksRoutes) GetLink() func(rw http.ResponseWriter, req *http.Request) {
-	templ := template.Must(template.ParseFS(templates, "templates/links.html"))
+	templ := template.Must(template.ParseFS(templates, "templates/links.html", "templates/layout.html"))
 	return func(rw http.ResponseWriter, req *http.Request) {
 		idStr := req.PathValue("id")
 		if idStr == "" {
@@ -205,3 +271,192 @@ func (a *LinksRoutes) GetLink() func(rw http.ResponseWriter, req *http.Request)
 		}
 	}
 }
+
+// validateSelector requires a selector for every link type but structured data, where it only narrows the product.
+func validateSelector(linkDesc link.LinkDescription) error {
+	if linkDesc.PriceSelector == "" && linkDesc.LinkType != link.LinkTypeStructured {
+		return fmt.Errorf("a price selector is required for %s links", linkDesc.LinkType)
+	}
+	return nil
+}
+
+// linkFromForm reads and validates the link submitted by the create and edit forms.
+func linkFromForm(req *http.Request) (link.LinkDescription, error) {
+	linkDesc, err := linkTargetFromForm(req)
+	if err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	if err := validateSelector(linkDesc); err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	return linkDesc, nil
+}
+
+// linkTargetFromForm reads and validates the submitted link apart from its selector:
+// what to fetch, for which product and country, and when.
+func linkTargetFromForm(req *http.Request) (link.LinkDescription, error) {
+	if err := req.ParseForm(); err != nil {
+		return link.LinkDescription{}, fmt.Errorf("failed to parse form: %w", err)
+	}
+
+	requestConfig, steps, err := parseRequest(req)
+	if err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	fallbacks, err := parseFallbacks(req.FormValue("fallbacks"))
+	if err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	linkDesc := link.LinkDescription{
+		ProductName:   req.FormValue("product_name"),
+		URL:           req.FormValue("url"),
+		LinkType:      link.LinkType(req.FormValue("link_type")),
+		PriceSelector: req.FormValue("price_selector"),
+		CountryCode:   req.FormValue("country_code"),
+		Schedule:      req.FormValue("schedule"),
+		Request:       requestConfig,
+		Steps:         steps,
+		Fallbacks:     fallbacks,
+	}
+
+	if err := poller.ValidateRequests(linkDesc); err != nil {
+		return link.LinkDescription{}, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if err := validateSchedule(linkDesc.Schedule); err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	return linkDesc, nil
+}
+
+// parseRequest reads the optional request fields of the link form. Headers are given one
+// "Name: value" per line, query parameters one "name=value" per line and steps as a JSON array.
+func parseRequest(req *http.Request) (link.RequestConfig, []link.Step, error) {
+	cfg := link.RequestConfig{
+		Method: strings.ToUpper(strings.TrimSpace(req.FormValue("request_method"))),
+		Body:   req.FormValue("request_body"),
+	}
+	if cfg.Method == http.MethodGet {
+		cfg.Method = ""
+	}
+
+	var err error
+	cfg.Headers, err = parseParams(req.FormValue("request_headers"), ":")
+	if err != nil {
+		return link.RequestConfig{}, nil, fmt.Errorf("invalid headers: %w", err)
+	}
+	cfg.Query, err = parseParams(req.FormValue("request_query"), "=")
+	if err != nil {
+		return link.RequestConfig{}, nil, fmt.Errorf("invalid query parameters: %w", err)
+	}
+
+	if timeout := strings.TrimSpace(req.FormValue("request_timeout")); timeout != "" {
+		cfg.Timeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			return link.RequestConfig{}, nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	steps, err := parseSteps(req.FormValue("steps"))
+	if err != nil {
+		return link.RequestConfig{}, nil, fmt.Errorf("invalid steps: %w", err)
+	}
+
+	return cfg, steps, nil
+}
+
+var stepNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func parseSteps(text string) ([]link.Step, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.DisallowUnknownFields()
+
+	var steps []link.Step
+	if err := decoder.Decode(&steps); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if !stepNamePattern.MatchString(step.Name) {
+			return nil, fmt.Errorf("step %d: name %q must be a letter or underscore followed by letters, digits or underscores", i+1, step.Name)
+		}
+		if names[step.Name] {
+			return nil, fmt.Errorf("step %d: duplicate name %q", i+1, step.Name)
+		}
+		names[step.Name] = true
+
+		if step.URL == "" || step.LinkType == "" {
+			return nil, fmt.Errorf("step %s: url and link_type are required", step.Name)
+		}
+		if err := validateSelector(link.LinkDescription{LinkType: step.LinkType, PriceSelector: step.Selector}); err != nil {
+			return nil, fmt.Errorf("step %s: %w", step.Name, err)
+		}
+	}
+
+	return steps, nil
+}
+
+// parseFallbacks reads the fallback extractions of the link form, given as a JSON array.
+func parseFallbacks(text string) ([]link.Extraction, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.DisallowUnknownFields()
+
+	var fallbacks []link.Extraction
+	if err := decoder.Decode(&fallbacks); err != nil {
+		return nil, fmt.Errorf("invalid fallbacks: %w", err)
+	}
+
+	for i, fallback := range fallbacks {
+		if fallback.LinkType == "" {
+			return nil, fmt.Errorf("invalid fallbacks: fallback %d: link_type is required", i+1)
+		}
+		if err := validateSelector(link.LinkDescription{LinkType: fallback.LinkType, PriceSelector: fallback.Selector}); err != nil {
+			return nil, fmt.Errorf("invalid fallbacks: fallback %d: %w", i+1, err)
+		}
+	}
+
+	return fallbacks, nil
+}
+
+func parseParams(text, sep string) ([]link.Param, error) {
+	var params []link.Param
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, sep)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected %q in line %q", "name"+sep+"value", line)
+		}
+		params = append(params, link.Param{Name: name, Value: strings.TrimSpace(value)})
+	}
+	return params, nil
+}
+
+func validateSchedule(schedule string) error {
+	if schedule == "" {
+		return nil
+	}
+
+	if _, err := scheduler.Parse(schedule); err != nil {
+		return fmt.Errorf("invalid schedule: %w", err)
+	}
+	return nil
+}
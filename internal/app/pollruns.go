@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+)
+
+const recentPollRunsLimit = 50
+
+type PollRunsLister interface {
+	ListRuns(ctx context.Context, limit uint64) ([]pollrun.Run, error)
+	GetRunByID(ctx context.Context, ID pollrun.ID) (pollrun.Run, error)
+}
+
+type ResponseArchive interface {
+	Get(key string) (archive.Record, error)
+	Open(key string) (*os.File, error)
+}
+
+type PollRunsRoutes struct {
+	pollRunsRepo    PollRunsLister
+	responseArchive ResponseArchive
+}
+
+func NewPollRunsRoutes(pollRunsRepo PollRunsLister, responseArchive ResponseArchive) *PollRunsRoutes {
+	return &PollRunsRoutes{
+		pollRunsRepo:    pollRunsRepo,
+		responseArchive: responseArchive,
+	}
+}
+
+func (a *PollRunsRoutes) GetPollRuns() func(rw http.ResponseWriter, req *http.Request) {
+	templ := template.Must(template.ParseFS(templates, "templates/poll-runs.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		runs, err := a.pollRunsRepo.ListRuns(req.Context(), recentPollRunsLimit)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to list poll runs: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			Runs []pollrun.Run
+		}{
+			Runs: runs,
+		}
+
+		err = templ.Execute(rw, data)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render poll runs: %w", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (a *PollRunsRoutes) GetPollRun() func(rw http.ResponseWriter, req *http.Request) {
+	templ := template.Must(template.ParseFS(templates, "templates/poll-runs.html", "templates/layout.html"))
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		idStr := req.PathValue("id")
+		if idStr == "" {
+			renderError(rw, fmt.Errorf("missing ID"), http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			renderError(rw, fmt.Errorf("invalid ID format: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		run, err := a.pollRunsRepo.GetRunByID(req.Context(), pollrun.ID(id))
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to get poll run: %w", err), http.StatusInternalServerError)
+			return
+		}
+
+		err = templ.ExecuteTemplate(rw, "poll-run-attempts", run)
+		if err != nil {
+			renderError(rw, fmt.Errorf("failed to render poll run: %w", err), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetArchivedResponse serves the body of an archived response with its original content type.
+// Archived pages are untrusted, so the browser renders them sandboxed, without scripts or access to the app.
+func (a *PollRunsRoutes) GetArchivedResponse(rw http.ResponseWriter, req *http.Request) {
+	rec, err := a.responseArchive.Get(req.PathValue("key"))
+	if err != nil {
+		renderError(rw, fmt.Errorf("failed to read archived response: %w", err), archiveErrorStatus(err))
+		return
+	}
+
+	contentType := rec.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("Content-Security-Policy", "sandbox")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(rec.Body)
+}
+
+// DownloadArchivedResponse serves the WARC file of an archived response, headers included.
+func (a *PollRunsRoutes) DownloadArchivedResponse(rw http.ResponseWriter, req *http.Request) {
+	key := req.PathValue("key")
+	f, err := a.responseArchive.Open(key)
+	if err != nil {
+		renderError(rw, fmt.Errorf("failed to open archived response: %w", err), archiveErrorStatus(err))
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		renderError(rw, fmt.Errorf("failed to open archived response: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/gzip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", key+".warc.gz"))
+	http.ServeContent(rw, req, key+".warc.gz", info.ModTime(), f)
+}
+
+func archiveErrorStatus(err error) int {
+	if errors.Is(err, archive.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
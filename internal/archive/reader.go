@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reader reads the responses of a WARC file, such as the ones written by Store or those of web
+// archive dumps. Both plain and gzipped files are read; records other than responses and resources
+// (e.g. warcinfo, request or revisit records) are skipped.
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		// Every record of a .warc.gz file is its own gzip member; the reader reads them as one stream.
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(zr)
+	}
+
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next response record, or io.EOF when there are no more.
+func (r *Reader) Next() (Record, error) {
+	for {
+		version, err := r.readVersion()
+		if err != nil {
+			return Record{}, err
+		}
+		if !strings.HasPrefix(version, "WARC/") {
+			return Record{}, fmt.Errorf("not a WARC record: %q", version)
+		}
+
+		tp := textproto.NewReader(r.r)
+		warcHeader, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid WARC header: %w", err)
+		}
+
+		length, err := strconv.ParseInt(warcHeader.Get("Content-Length"), 10, 64)
+		if err != nil {
+			return Record{}, fmt.Errorf("invalid WARC Content-Length: %w", err)
+		}
+		block := make([]byte, length)
+		if _, err := io.ReadFull(r.r, block); err != nil {
+			return Record{}, fmt.Errorf("truncated WARC record: %w", err)
+		}
+
+		switch warcHeader.Get("WARC-Type") {
+		case "response":
+			return parseResponse(warcHeader, block)
+		case "resource":
+			date, err := parseDate(warcHeader)
+			if err != nil {
+				return Record{}, err
+			}
+			return Record{
+				TargetURI:  warcHeader.Get("WARC-Target-URI"),
+				Date:       date,
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {warcHeader.Get("Content-Type")}},
+				Body:       block,
+			}, nil
+		}
+	}
+}
+
+// readVersion reads the first line of a record, skipping the blank lines that end the previous one.
+func (r *Reader) readVersion() (string, error) {
+	for {
+		line, err := r.r.ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			return line, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func parseResponse(warcHeader textproto.MIMEHeader, block []byte) (Record, error) {
+	date, err := parseDate(warcHeader)
+	if err != nil {
+		return Record{}, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid archived response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid archived response body: %w", err)
+	}
+	// Crawlers archive bodies as sent; ours are stored decompressed already.
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		if zr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(zr); err == nil {
+				body = decoded
+			}
+		}
+	}
+
+	return Record{
+		TargetURI:  warcHeader.Get("WARC-Target-URI"),
+		Date:       date,
+		Proto:      resp.Proto,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}, nil
+}
+
+func parseDate(warcHeader textproto.MIMEHeader) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, warcHeader.Get("WARC-Date"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid WARC-Date: %w", err)
+	}
+	return date, nil
+}
@@ -0,0 +1,208 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("archived response not found")
+
+var keyRe = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+const fileExt = ".warc.gz"
+
+// Record is an HTTP response as it was received.
+type Record struct {
+	// TargetURI is the requested URL. It is stored and served as given, so it must not hold secrets.
+	TargetURI  string
+	Date       time.Time
+	Proto      string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Store keeps responses as single-record gzipped WARC files, named after a digest of what parsing
+// the response depends on, so an unchanged page is stored once however often it is fetched.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Put archives rec and returns its key. Archiving a response whose key is already stored keeps the
+// original record, with its date and headers, and only renews its modification time, which
+// retention is based on.
+func (s *Store) Put(rec Record) (string, error) {
+	key := recordKey(rec)
+	path := s.path(key)
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err == nil {
+		return key, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated record under the key.
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeRecord(tmp, rec); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// Get reads back the response archived under key.
+func (s *Store) Get(key string) (Record, error) {
+	f, err := s.Open(key)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return Record{}, err
+	}
+	return r.Next()
+}
+
+// Open returns the gzipped WARC file archived under key.
+func (s *Store) Open(key string) (*os.File, error) {
+	if !keyRe.MatchString(key) {
+		return nil, fmt.Errorf("%w: invalid key %q", ErrNotFound, key)
+	}
+
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return f, err
+}
+
+// Prune deletes the responses last archived before cutoff and returns how many were deleted.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	pruned := 0
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, fileExt) {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+
+	return pruned, err
+}
+
+// recordKey returns the key rec is archived under: the SHA-256 of its URL, status, content type and body.
+// Headers such as Date or Set-Cookie change on every fetch and are left out, so refetching an
+// unchanged page gives the same key.
+func recordKey(rec Record) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%d\n%s\n\n", rec.TargetURI, rec.StatusCode, rec.Header.Get("Content-Type"))
+	h.Write(rec.Body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path spreads records over subdirectories named after the first two characters of their key.
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key+fileExt)
+}
+
+// httpBlock serializes the response the way it appeared on the wire, as WARC expects.
+func httpBlock(rec Record) []byte {
+	var buf bytes.Buffer
+	proto := rec.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	fmt.Fprintf(&buf, "%s %d %s\r\n", proto, rec.StatusCode, http.StatusText(rec.StatusCode))
+	rec.Header.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(rec.Body)
+	return buf.Bytes()
+}
+
+func writeRecord(w io.Writer, rec Record) error {
+	recordID, err := newRecordID()
+	if err != nil {
+		return err
+	}
+	block := httpBlock(rec)
+	blockDigest := sha256.Sum256(block)
+	payloadDigest := sha1.Sum(rec.Body)
+
+	zw := gzip.NewWriter(w)
+	fmt.Fprintf(zw, "WARC/1.1\r\n")
+	fmt.Fprintf(zw, "WARC-Type: response\r\n")
+	fmt.Fprintf(zw, "WARC-Record-ID: %s\r\n", recordID)
+	fmt.Fprintf(zw, "WARC-Date: %s\r\n", rec.Date.UTC().Format(time.RFC3339))
+	fmt.Fprintf(zw, "WARC-Target-URI: %s\r\n", rec.TargetURI)
+	fmt.Fprintf(zw, "WARC-Block-Digest: sha256:%s\r\n", hex.EncodeToString(blockDigest[:]))
+	fmt.Fprintf(zw, "WARC-Payload-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(payloadDigest[:]))
+	fmt.Fprintf(zw, "Content-Type: application/http;msgtype=response\r\n")
+	fmt.Fprintf(zw, "Content-Length: %d\r\n\r\n", len(block))
+	zw.Write(block)
+	zw.Write([]byte("\r\n\r\n"))
+
+	// The gzip writer keeps the first write error and returns it from Close.
+	return zw.Close()
+}
+
+// newRecordID returns a random (version 4) UUID URN.
+func newRecordID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
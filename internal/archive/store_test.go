@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStorePutDeduplicatesUnchangedPages(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	fetch := func(date time.Time, cookie, body string) Record {
+		return Record{
+			TargetURI:  "https://example.com/menu",
+			Date:       date,
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": {"text/html; charset=utf-8"},
+				"Date":         {date.Format(http.TimeFormat)},
+				"Set-Cookie":   {"session=" + cookie},
+			},
+			Body: []byte(body),
+		}
+	}
+
+	first := fetch(time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC), "a", "<p>5.69</p>")
+	firstKey, err := store.Put(first)
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	secondKey, err := store.Put(fetch(time.Date(2026, 1, 2, 6, 0, 0, 0, time.UTC), "b", "<p>5.69</p>"))
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if secondKey != firstKey {
+		t.Errorf("refetched page got key %s, want %s", secondKey, firstKey)
+	}
+
+	changedKey, err := store.Put(fetch(time.Date(2026, 1, 3, 6, 0, 0, 0, time.UTC), "c", "<p>5.79</p>"))
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	if changedKey == firstKey {
+		t.Error("changed page got the key of the original one")
+	}
+
+	got, err := store.Get(firstKey)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if !got.Date.Equal(first.Date) || string(got.Body) != string(first.Body) || got.Header.Get("Set-Cookie") != "session=a" {
+		t.Errorf("Get = %+v, want the first record %+v", got, first)
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	key, err := store.Put(Record{TargetURI: "https://example.com/", Date: time.Now(), StatusCode: http.StatusOK, Header: http.Header{}})
+	if err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(store.path(key), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := store.Prune(time.Now().Add(-24 * time.Hour))
+	if err != nil || pruned != 1 {
+		t.Fatalf("Prune = %d, %v, want 1", pruned, err)
+	}
+	if _, err := store.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a pruned record returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,267 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+	"github.com/turbak/bigmacindex/internal/poller"
+)
+
+var (
+	// waybackTimestampRe matches the 14-digit capture timestamps of the Wayback Machine, e.g. 20190315123456.
+	waybackTimestampRe = regexp.MustCompile(`(?:^|\D)(\d{14})(?:\D|$)`)
+	dateRe             = regexp.MustCompile(`(?:^|\D)(\d{4}-\d{2}-\d{2})(?:\D|$)`)
+)
+
+type SnapshotReplayer interface {
+	Replay(ctx context.Context, snapshots []poller.Snapshot) ([]poller.LinkOutcome, error)
+}
+
+type BackfilledPriceInserter interface {
+	InsertBackfilledPrice(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, bool, error)
+}
+
+type Options struct {
+	// CapturedAt dates the plain snapshot files whose names carry no capture timestamp.
+	CapturedAt time.Time
+	// AnyURL imports every response of a WARC file instead of only those of the link's URL.
+	AnyURL bool
+	DryRun bool
+}
+
+// Report lists what became of the snapshots of an import.
+type Report struct {
+	// Inserted are the prices saved, or that would have been on a dry run.
+	Inserted []price.PriceRecord
+	// Kept are the prices of days that already have a polled price, which is left as it is.
+	Kept     []price.PriceRecord
+	Failures []Failure
+	// Skipped counts WARC responses of other URLs.
+	Skipped int
+}
+
+// Failure is a snapshot no price could be read from.
+type Failure struct {
+	Ref        string
+	CapturedAt time.Time
+	Err        error
+}
+
+type snapshot struct {
+	ref      string
+	response archive.Record
+}
+
+type importer struct {
+	replayer SnapshotReplayer
+	inserter BackfilledPriceInserter
+}
+
+func NewImporter(replayer SnapshotReplayer, inserter BackfilledPriceInserter) *importer {
+	return &importer{
+		replayer: replayer,
+		inserter: inserter,
+	}
+}
+
+// ImportFiles reads the price of linkDesc from WARC files (.warc, .warc.gz) and plain snapshot files
+// such as saved HTML pages, and saves it dated by the capture time. When a day has several snapshots,
+// the last one that parses sets its price.
+func (i *importer) ImportFiles(ctx context.Context, linkDesc link.LinkDescription, paths []string, opts Options) (Report, error) {
+	var (
+		report    Report
+		snapshots []snapshot
+	)
+	for _, path := range paths {
+		var (
+			fileSnapshots []snapshot
+			skipped       int
+			err           error
+		)
+		if isWARC(path) {
+			fileSnapshots, skipped, err = readWARC(path, linkDesc.URL, opts.AnyURL)
+		} else {
+			fileSnapshots, err = readFile(path, opts.CapturedAt)
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		snapshots = append(snapshots, fileSnapshots...)
+		report.Skipped += skipped
+	}
+
+	slices.SortStableFunc(snapshots, func(a, b snapshot) int {
+		return a.response.Date.Compare(b.response.Date)
+	})
+
+	replaySnapshots := make([]poller.Snapshot, len(snapshots))
+	for idx, s := range snapshots {
+		replaySnapshots[idx] = poller.Snapshot{Link: linkDesc, Response: s.response, PolledAt: s.response.Date}
+	}
+	outcomes, err := i.replayer.Replay(ctx, replaySnapshots)
+	if err != nil {
+		return Report{}, err
+	}
+
+	// Snapshots are in capture order, so a later one of the same day replaces an earlier one.
+	var (
+		dates  []string
+		byDate = make(map[string]price.PriceRecord)
+	)
+	for idx, outcome := range outcomes {
+		if outcome.Err != nil {
+			report.Failures = append(report.Failures, Failure{Ref: snapshots[idx].ref, CapturedAt: snapshots[idx].response.Date, Err: outcome.Err})
+			continue
+		}
+
+		priceRec := outcome.Price
+		priceRec.Source = price.SourceBackfill
+		priceRec.SourceRef = snapshots[idx].ref
+		if _, ok := byDate[priceRec.CreatedDate]; !ok {
+			dates = append(dates, priceRec.CreatedDate)
+		}
+		byDate[priceRec.CreatedDate] = priceRec
+	}
+
+	for _, date := range dates {
+		priceRec := byDate[date]
+		if opts.DryRun {
+			report.Inserted = append(report.Inserted, priceRec)
+			continue
+		}
+
+		saved, inserted, err := i.inserter.InsertBackfilledPrice(ctx, priceRec)
+		if err != nil {
+			return report, fmt.Errorf("failed to save price of %s: %w", date, err)
+		}
+		if inserted {
+			report.Inserted = append(report.Inserted, saved)
+		} else {
+			report.Kept = append(report.Kept, saved)
+		}
+	}
+
+	return report, nil
+}
+
+func isWARC(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.HasSuffix(name, ".warc") || strings.HasSuffix(name, ".warc.gz")
+}
+
+// readWARC reads the responses of a WARC file for linkURL, or all of them when anyURL is set.
+// It also returns how many responses of other URLs were skipped.
+func readWARC(path, linkURL string, anyURL bool) ([]snapshot, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	r, err := archive.NewReader(f)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		snapshots []snapshot
+		skipped   int
+	)
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return snapshots, skipped, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if !anyURL && !sameURL(rec.TargetURI, linkURL) {
+			skipped++
+			continue
+		}
+		snapshots = append(snapshots, snapshot{
+			ref:      fmt.Sprintf("%s %s %s", filepath.Base(path), rec.Date.UTC().Format(time.RFC3339), rec.TargetURI),
+			response: rec,
+		})
+	}
+}
+
+// readFile reads a plain snapshot file, dated by a Wayback timestamp or a date in its name,
+// or by capturedAt when it has neither.
+func readFile(path string, capturedAt time.Time) ([]snapshot, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	capturedAt, err = captureTime(filepath.Base(path), capturedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make(http.Header)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		// No charset, so the page's own meta tag decides, as it does for a fetched page without one.
+		header.Set("Content-Type", "text/html")
+	case ".json":
+		header.Set("Content-Type", "application/json")
+	}
+
+	return []snapshot{{
+		ref:      filepath.Base(path),
+		response: archive.Record{Date: capturedAt, StatusCode: http.StatusOK, Header: header, Body: body},
+	}}, nil
+}
+
+func captureTime(name string, fallback time.Time) (time.Time, error) {
+	if m := waybackTimestampRe.FindStringSubmatch(name); m != nil {
+		if t, err := time.Parse("20060102150405", m[1]); err == nil {
+			return t, nil
+		}
+	}
+	if m := dateRe.FindStringSubmatch(name); m != nil {
+		if t, err := time.Parse(time.DateOnly, m[1]); err == nil {
+			return t, nil
+		}
+	}
+	if !fallback.IsZero() {
+		return fallback, nil
+	}
+
+	return time.Time{}, fmt.Errorf("no capture time in file name %q and no capture date given", name)
+}
+
+// sameURL reports whether two URLs address the same page, ignoring the scheme, a "www." prefix
+// and a trailing slash, which differ freely between archived captures.
+func sameURL(a, b string) bool {
+	return normalizeURL(a) == normalizeURL(b)
+}
+
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		return host + path + "?" + u.RawQuery
+	}
+	return host + path
+}
@@ -0,0 +1,78 @@
+package backfill
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCaptureTime(t *testing.T) {
+	fallback := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		file     string
+		fallback time.Time
+		want     time.Time
+		wantErr  bool
+	}{
+		{"wayback timestamp", "web.archive.org_20190315123456_mcdonalds.html", time.Time{}, time.Date(2019, 3, 15, 12, 34, 56, 0, time.UTC), false},
+		{"wayback timestamp alone", "20190315123456.html", time.Time{}, time.Date(2019, 3, 15, 12, 34, 56, 0, time.UTC), false},
+		{"date", "menu-2018-11-02.json", time.Time{}, time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC), false},
+		{"timestamp wins over date", "2018-11-02_20190315123456.html", time.Time{}, time.Date(2019, 3, 15, 12, 34, 56, 0, time.UTC), false},
+		{"invalid timestamp falls back to the date", "20191399999999_2018-11-02.html", time.Time{}, time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC), false},
+		{"longer digit run is not a timestamp", "id_123456789012345.html", fallback, fallback, false},
+		{"fallback", "menu.html", fallback, fallback, false},
+		{"invalid date uses the fallback", "menu-2018-13-45.html", fallback, fallback, false},
+		{"no time at all", "menu.html", time.Time{}, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := captureTime(tt.file, tt.fallback)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("captureTime(%q) error = %v, want error %v", tt.file, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("captureTime(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://www.mcdonalds.com/us/en-us/product/big-mac.html", "mcdonalds.com/us/en-us/product/big-mac.html"},
+		{"http://McDonalds.com/menu/", "mcdonalds.com/menu"},
+		{"  https://mcdonalds.co.jp/menu/burger/?id=1  ", "mcdonalds.co.jp/menu/burger?id=1"},
+		{"https://mcdonalds.com", "mcdonalds.com"},
+		{"https://mcdonalds.com/menu%20items", "mcdonalds.com/menu%20items"},
+		{"://broken", "://broken"},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeURL(tt.in); got != tt.want {
+			t.Errorf("normalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSameURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://www.mcdonalds.com/menu/", "http://mcdonalds.com/menu", true},
+		{"https://mcdonalds.com/menu?id=1", "https://mcdonalds.com/menu?id=2", false},
+		{"https://mcdonalds.com/menu", "https://mcdonalds.de/menu", false},
+		{"https://mcdonalds.com/menu", "https://mcdonalds.com/Menu", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameURL(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameURL(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package country
+
+type Country struct {
+	Code         string `db:"code"`
+	Name         string `db:"name"`
+	CurrencyCode string `db:"currency_code"`
+}
@@ -0,0 +1,8 @@
+package currency
+
+type Currency struct {
+	Code       string   `db:"code"`
+	Name       string   `db:"name"`
+	MinorUnits int      `db:"minor_units"`
+	Symbols    []string `db:"-"`
+}
@@ -0,0 +1,12 @@
+package fxrate
+
+type ID int32
+
+// Rate is the amount of Quote currency that one unit of Base currency buys on Date.
+type Rate struct {
+	ID    ID      `db:"id"`
+	Date  string  `db:"date"`
+	Base  string  `db:"base"`
+	Quote string  `db:"quote"`
+	Rate  float64 `db:"rate"`
+}
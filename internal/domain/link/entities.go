@@ -8,6 +8,11 @@ const (
 	LinkTypeJSON  LinkType = "json"
 	LinkTypeHTML  LinkType = "html"
 	LinkTypeRegex LinkType = "regex"
+	LinkTypeCSS   LinkType = "css"
+	// LinkTypeStructured reads schema.org price data; its selector optionally names the product.
+	LinkTypeStructured LinkType = "structured"
+	// LinkTypeEmbeddedJSON applies a JSONPath to JSON found in the page; its selector is "locator | jsonpath".
+	LinkTypeEmbeddedJSON LinkType = "embedded_json"
 )
 
 type LinkDescription struct {
@@ -17,4 +22,26 @@ type LinkDescription struct {
 	LinkType      LinkType `db:"link_type"`
 	PriceSelector string   `db:"price_selector"`
 	CountryCode   string   `db:"country_code"`
+	// Schedule is a cron expression overriding the poller's global schedule; empty uses the global one.
+	Schedule string        `db:"schedule"`
+	Request  RequestConfig `db:"request_config"`
+	// Steps run in order before the link's request, see Step.
+	Steps []Step `db:"steps"`
+	// Fallbacks are tried in order when LinkType and PriceSelector find no price.
+	Fallbacks []Extraction `db:"fallbacks"`
+}
+
+// Extraction is a way of reading a value from a response: a selector interpreted as per LinkType.
+type Extraction struct {
+	LinkType LinkType `json:"link_type"`
+	Selector string   `json:"selector"`
+}
+
+func (e Extraction) String() string {
+	return string(e.LinkType) + ": " + e.Selector
+}
+
+// Extractions returns the link's own extraction followed by its fallbacks.
+func (d LinkDescription) Extractions() []Extraction {
+	return append([]Extraction{{LinkType: d.LinkType, Selector: d.PriceSelector}}, d.Fallbacks...)
 }
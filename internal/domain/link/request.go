@@ -0,0 +1,59 @@
+package link
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RequestConfig customizes the HTTP request made for a link. Query values, header values and the body
+// are Go templates over the link, e.g. `{"country": "{{ .CountryCode }}"}` or `Bearer {{ env "BIGMAC_API_KEY" }}`.
+// The zero value is a plain GET with the poller's default timeout.
+type RequestConfig struct {
+	// Method defaults to GET.
+	Method  string        `json:"method,omitempty"`
+	Headers []Param       `json:"headers,omitempty"`
+	Query   []Param       `json:"query,omitempty"`
+	Body    string        `json:"body,omitempty"`
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+type Param struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (c RequestConfig) IsZero() bool {
+	return c.Method == "" && len(c.Headers) == 0 && len(c.Query) == 0 && c.Body == "" && c.Timeout == 0
+}
+
+// Step is a request made before the link's own one, e.g. to get a session token or pick a store.
+// The value its Extraction reads from the response is available to the templates of
+// later steps and of the link's request as {{ .Vars.<Name> }}.
+type Step struct {
+	Name    string        `json:"name"`
+	URL     string        `json:"url"`
+	Request RequestConfig `json:"request,omitzero"`
+	Extraction
+}
+
+// StepsJSON returns the steps as indented JSON, the format the links form edits them in.
+func (d LinkDescription) StepsJSON() string {
+	return indentedJSON(d.Steps)
+}
+
+// FallbacksJSON returns the fallbacks as indented JSON, the format the links form edits them in.
+func (d LinkDescription) FallbacksJSON() string {
+	return indentedJSON(d.Fallbacks)
+}
+
+func indentedJSON[T any](values []T) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
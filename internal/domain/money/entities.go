@@ -0,0 +1,93 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money is an exact amount of a currency, held as an integer number of the currency's minor units,
+// e.g. 499 with 2 minor units is 4.99 USD and 1250 with 3 minor units is 1.250 KWD.
+type Money struct {
+	Amount     int64
+	Currency   string
+	MinorUnits int
+}
+
+func New(amount int64, currency string, minorUnits int) Money {
+	return Money{Amount: amount, Currency: currency, MinorUnits: minorUnits}
+}
+
+// FromFloat rounds value to the nearest minor unit of currency, halves away from zero. The shortest
+// decimal form of value is scaled rather than value itself, so 1.005 becomes 1.01 although the float
+// closest to it is 1.00499….
+func FromFloat(value float64, currency string, minorUnits int) Money {
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(value, 'e', -1, 64), "e")
+	exp, err := strconv.Atoi(exponent)
+	scaled := value * math.Pow10(minorUnits)
+	if err == nil {
+		scaled, _ = strconv.ParseFloat(mantissa+"e"+strconv.Itoa(exp+minorUnits), 64)
+	}
+	return New(int64(math.Round(scaled)), currency, minorUnits)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Float64 returns the amount in major units. It is meant for ratios and display, not for storage.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / math.Pow10(m.MinorUnits)
+}
+
+// Convert converts m to currency at rate, the number of currency units per one unit of m's currency.
+func (m Money) Convert(rate float64, currency string, minorUnits int) Money {
+	return FromFloat(m.Float64()*rate, currency, minorUnits)
+}
+
+// Compare returns -1, 0 or 1 as m is less than, equal to or greater than other.
+// Amounts of different currencies can't be compared and return ErrCurrencyMismatch.
+func (m Money) Compare(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	minorUnits := max(m.MinorUnits, other.MinorUnits)
+	a, b := m.rescale(minorUnits), other.rescale(minorUnits)
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Format returns the amount with all of its minor-unit digits and no currency, e.g. "4.99" or "199".
+func (m Money) Format() string {
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if m.MinorUnits <= 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+
+	unit := int64(math.Pow10(m.MinorUnits))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, m.MinorUnits, amount%unit)
+}
+
+func (m Money) String() string {
+	return m.Format() + " " + m.Currency
+}
+
+func (m Money) rescale(minorUnits int) int64 {
+	return m.Amount * int64(math.Pow10(minorUnits-m.MinorUnits))
+}
@@ -0,0 +1,94 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		value      float64
+		minorUnits int
+		want       int64
+	}{
+		{5.69, 2, 569},
+		{0.1 + 0.2, 2, 30},
+		{4.995, 2, 500},
+		{1.005, 2, 101},
+		{-4.995, 2, -500},
+		{390.4, 0, 390},
+		{390.5, 0, 391},
+		{1.2505, 3, 1251},
+		{1.2504, 3, 1250},
+		{12345678.91, 2, 1234567891},
+		{1e-5, 2, 0},
+		{2.5e6, 2, 250000000},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.value, "XXX", tt.minorUnits); got.Amount != tt.want {
+			t.Errorf("FromFloat(%v, %d) = %d, want %d", tt.value, tt.minorUnits, got.Amount, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		from Money
+		rate float64
+		want Money
+	}{
+		{New(710, "CHF", 2), 1.25, New(888, "USD", 2)},
+		{New(480, "JPY", 0), 1 / 150.0, New(320, "USD", 2)},
+		{New(569, "USD", 2), 0.31, New(1764, "KWD", 3)},
+		{New(569, "USD", 2), 156.3, New(889, "JPY", 0)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.Convert(tt.rate, tt.want.Currency, tt.want.MinorUnits); got != tt.want {
+			t.Errorf("%s.Convert(%v) = %s, want %s", tt.from, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(569, "USD", 2), "5.69 USD"},
+		{New(5, "USD", 2), "0.05 USD"},
+		{New(-569, "USD", 2), "-5.69 USD"},
+		{New(390, "JPY", 0), "390 JPY"},
+		{New(1250, "KWD", 3), "1.250 KWD"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b Money
+		want int
+	}{
+		{New(569, "USD", 2), New(569, "USD", 2), 0},
+		{New(1250, "KWD", 3), New(125, "KWD", 2), 0},
+		{New(1251, "KWD", 3), New(125, "KWD", 2), 1},
+		{New(390, "JPY", 0), New(391, "JPY", 0), -1},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.a.Compare(tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	if _, err := New(569, "USD", 2).Compare(New(569, "CAD", 2)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Compare of different currencies returned %v, want %v", err, ErrCurrencyMismatch)
+	}
+}
@@ -0,0 +1,52 @@
+package pollrun
+
+import (
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+type ID int32
+
+type Run struct {
+	ID         ID        `db:"id"`
+	StartedAt  time.Time `db:"started_at"`
+	FinishedAt time.Time `db:"finished_at"`
+	Succeeded  int       `db:"succeeded"`
+	Failed     int       `db:"failed"`
+	// Fallbacks counts the succeeded attempts whose price was found by a fallback selector.
+	Fallbacks int       `db:"fallbacks"`
+	Attempts  []Attempt `db:"-"`
+}
+
+// Attempt is the record of polling a single link within a run. ErrorKind and Error are empty on success.
+type Attempt struct {
+	ID          ID            `db:"id"`
+	RunID       ID            `db:"run_id"`
+	LinkID      link.ID       `db:"link_id"`
+	ProductName string        `db:"product_name"`
+	CountryCode string        `db:"country_code"`
+	URL         string        `db:"url"`
+	HTTPStatus  int           `db:"http_status"`
+	Latency     time.Duration `db:"latency_ms"`
+	RawValue    string        `db:"raw_value"`
+	ParsedPrice string        `db:"parsed_price"`
+	ErrorKind   string        `db:"error_kind"`
+	Error       string        `db:"error"`
+	CreatedAt   time.Time     `db:"created_at"`
+	// MatchedRule is 0 when the link's own selector found the price and n when its nth fallback did.
+	MatchedRule     int    `db:"matched_rule"`
+	MatchedSelector string `db:"matched_selector"`
+	// ArchiveKeys are the archived responses of the attempt, including those of its steps and retries.
+	ArchiveKeys []string `db:"archive_keys"`
+	// ResponseKey is the archived response of the link's own request, empty when that request got none.
+	ResponseKey string `db:"response_key"`
+}
+
+func (a Attempt) Failed() bool {
+	return a.ErrorKind != ""
+}
+
+func (a Attempt) UsedFallback() bool {
+	return a.MatchedRule > 0
+}
@@ -1,13 +1,36 @@
 package price
 
+import "github.com/turbak/bigmacindex/internal/domain/money"
+
 type ID int32
 
+// Source is where a price came from.
+type Source string
+
+const (
+	SourcePoll     Source = "poll"
+	SourceBackfill Source = "backfill"
+)
+
 type PriceRecord struct {
-	ID          ID     `db:"id"`
-	ProductName string `db:"product_name"`
-	Price       int32  `db:"price"`
-	PriceCents  int32  `db:"price_cents"`
-	Currency    string `db:"currency"`
-	CountryCode string `db:"country_code"`
-	CreatedDate string `db:"created_date"`
+	ID          ID          `db:"id"`
+	ProductName string      `db:"product_name"`
+	Price       money.Money `db:"-"`
+	CountryCode string      `db:"country_code"`
+	CreatedDate string      `db:"created_date"`
+	Source      Source      `db:"source"`
+	// SourceRef identifies the snapshot a backfilled price was read from.
+	SourceRef string `db:"source_ref"`
+}
+
+func (p PriceRecord) Backfilled() bool {
+	return p.Source == SourceBackfill
+}
+
+// Filter narrows price listings. Empty fields match everything; From and To are inclusive dates.
+type Filter struct {
+	CountryCode string
+	ProductName string
+	From        string
+	To          string
 }
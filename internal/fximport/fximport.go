@@ -0,0 +1,117 @@
+package fximport
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+	"github.com/turbak/bigmacindex/internal/fximport/importers"
+)
+
+type Format string
+
+const (
+	FormatECBXML Format = "ecb-xml"
+	FormatECBCSV Format = "ecb-csv"
+	FormatCSV    Format = "csv"
+)
+
+type RatesUpserter interface {
+	UpsertRates(ctx context.Context, rates []fxrate.Rate) (int, error)
+}
+
+type fxImporter struct {
+	ratesUpserter     RatesUpserter
+	importersByFormat map[Format]Importer
+}
+
+func NewFXImporter(ratesUpserter RatesUpserter) *fxImporter {
+	return &fxImporter{
+		ratesUpserter: ratesUpserter,
+		importersByFormat: map[Format]Importer{
+			FormatECBXML: importers.ECBXMLImporter{},
+			FormatECBCSV: importers.ECBCSVImporter{},
+			FormatCSV:    importers.CSVImporter{},
+		},
+	}
+}
+
+// ImportFile loads rates from a local file, unpacking zip archives such as ECB's eurofxref-hist.zip.
+// An empty format is inferred from the file name.
+func (i *fxImporter) ImportFile(ctx context.Context, path string, format Format) (int, error) {
+	if format == "" {
+		format = detectFormat(path)
+	}
+
+	importer, ok := i.importersByFormat[format]
+	if !ok {
+		return 0, fmt.Errorf("no importer for format %s", format)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return i.importZip(ctx, path, importer)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return i.importReader(ctx, file, importer)
+}
+
+func (i *fxImporter) importZip(ctx context.Context, path string, importer Importer) (int, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
+
+	var imported int
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return imported, err
+		}
+
+		n, err := i.importReader(ctx, reader, importer)
+		reader.Close()
+		if err != nil {
+			return imported, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		imported += n
+	}
+
+	return imported, nil
+}
+
+func (i *fxImporter) importReader(ctx context.Context, reader io.Reader, importer Importer) (int, error) {
+	rates, err := importer.ImportRatesFromReader(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	return i.ratesUpserter.UpsertRates(ctx, rates)
+}
+
+func detectFormat(path string) Format {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".xml"):
+		return FormatECBXML
+	case strings.HasPrefix(name, "eurofxref"):
+		return FormatECBCSV
+	default:
+		return FormatCSV
+	}
+}
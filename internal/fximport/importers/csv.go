@@ -0,0 +1,70 @@
+package importers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+)
+
+var csvColumns = []string{"date", "base", "quote", "rate"}
+
+// CSVImporter reads rates from a CSV file with date, base, quote and rate columns in any order,
+// where rate is the amount of quote currency bought by one unit of base currency.
+type CSVImporter struct{}
+
+func (i CSVImporter) ImportRatesFromReader(reader io.Reader) ([]fxrate.Rate, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columnIdx := make(map[string]int, len(header))
+	for idx, name := range header {
+		columnIdx[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	for _, name := range csvColumns {
+		if _, ok := columnIdx[name]; !ok {
+			return nil, fmt.Errorf("missing %s column in header %v", name, header)
+		}
+	}
+
+	var rates []fxrate.Rate
+	for line := 2; ; line++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date := strings.TrimSpace(record[columnIdx["date"]])
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", line, date)
+		}
+
+		rateStr := strings.TrimSpace(record[columnIdx["rate"]])
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, rateStr)
+		}
+
+		rates = append(rates, fxrate.Rate{
+			Date:  date,
+			Base:  strings.ToUpper(strings.TrimSpace(record[columnIdx["base"]])),
+			Quote: strings.ToUpper(strings.TrimSpace(record[columnIdx["quote"]])),
+			Rate:  rate,
+		})
+	}
+
+	return rates, nil
+}
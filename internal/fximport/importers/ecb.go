@@ -0,0 +1,139 @@
+package importers
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+)
+
+// ecbBase is the base currency of every ECB euro foreign exchange reference rate.
+const ecbBase = "EUR"
+
+// ecbDateLayouts covers the daily eurofxref.csv ("05 January 2024") and the historical files ("2024-01-05").
+var ecbDateLayouts = []string{time.DateOnly, "02 January 2006", "2 January 2006"}
+
+// ECBXMLImporter reads eurofxref-daily.xml, eurofxref-hist.xml and eurofxref-hist-90d.xml.
+type ECBXMLImporter struct{}
+
+func (i ECBXMLImporter) ImportRatesFromReader(reader io.Reader) ([]fxrate.Rate, error) {
+	decoder := xml.NewDecoder(reader)
+
+	var (
+		rates []fxrate.Rate
+		date  string
+	)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		elem, ok := token.(xml.StartElement)
+		if !ok || elem.Name.Local != "Cube" {
+			continue
+		}
+
+		var currency, rate string
+		for _, attr := range elem.Attr {
+			switch attr.Name.Local {
+			case "time":
+				date, err = parseECBDate(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+			case "currency":
+				currency = attr.Value
+			case "rate":
+				rate = attr.Value
+			}
+		}
+
+		if currency == "" {
+			continue
+		}
+		if date == "" {
+			return nil, fmt.Errorf("rate for %s appears before any date", currency)
+		}
+
+		value, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s rate %q on %s: %w", currency, rate, date, err)
+		}
+
+		rates = append(rates, fxrate.Rate{Date: date, Base: ecbBase, Quote: currency, Rate: value})
+	}
+
+	return rates, nil
+}
+
+// ECBCSVImporter reads eurofxref.csv and eurofxref-hist.csv: a Date column followed by one column per currency.
+type ECBCSVImporter struct{}
+
+func (i ECBCSVImporter) ImportRatesFromReader(reader io.Reader) ([]fxrate.Rate, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(header) == 0 || !strings.EqualFold(strings.TrimSpace(header[0]), "Date") {
+		return nil, fmt.Errorf("expected the first column to be Date, got %v", header)
+	}
+
+	var rates []fxrate.Rate
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		date, err := parseECBDate(record[0])
+		if err != nil {
+			return nil, err
+		}
+
+		for col := 1; col < len(record) && col < len(header); col++ {
+			currency := strings.TrimSpace(header[col])
+			value := strings.TrimSpace(record[col])
+			// ECB pads rows with a trailing comma and marks discontinued currencies as N/A.
+			if currency == "" || value == "" || value == "N/A" {
+				continue
+			}
+
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s rate %q on %s: %w", currency, value, date, err)
+			}
+
+			rates = append(rates, fxrate.Rate{Date: date, Base: ecbBase, Quote: currency, Rate: rate})
+		}
+	}
+
+	return rates, nil
+}
+
+func parseECBDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range ecbDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.DateOnly), nil
+		}
+	}
+
+	return "", fmt.Errorf("unrecognized date %q", value)
+}
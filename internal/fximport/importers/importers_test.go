@@ -0,0 +1,101 @@
+package importers
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+)
+
+type importer interface {
+	ImportRatesFromReader(reader io.Reader) ([]fxrate.Rate, error)
+}
+
+func TestImporters(t *testing.T) {
+	tests := []struct {
+		name     string
+		importer importer
+		feed     string
+		want     []fxrate.Rate
+	}{
+		{
+			name:     "ECB daily XML",
+			importer: ECBXMLImporter{},
+			feed: `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-01-05">
+			<Cube currency="USD" rate="1.1720"/>
+			<Cube currency="JPY" rate="183.42"/>
+		</Cube>
+		<Cube time="2026-01-02">
+			<Cube currency="USD" rate="1.1734"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`,
+			want: []fxrate.Rate{
+				{Date: "2026-01-05", Base: "EUR", Quote: "USD", Rate: 1.172},
+				{Date: "2026-01-05", Base: "EUR", Quote: "JPY", Rate: 183.42},
+				{Date: "2026-01-02", Base: "EUR", Quote: "USD", Rate: 1.1734},
+			},
+		},
+		{
+			name:     "ECB daily CSV",
+			importer: ECBCSVImporter{},
+			feed:     "Date, USD, JPY, CYP, \n05 January 2026, 1.1720, 183.42, N/A, \n",
+			want: []fxrate.Rate{
+				{Date: "2026-01-05", Base: "EUR", Quote: "USD", Rate: 1.172},
+				{Date: "2026-01-05", Base: "EUR", Quote: "JPY", Rate: 183.42},
+			},
+		},
+		{
+			name:     "ECB historical CSV",
+			importer: ECBCSVImporter{},
+			feed:     "Date,USD,\n2026-01-05,1.1720,\n2026-01-02,1.1734,\n",
+			want: []fxrate.Rate{
+				{Date: "2026-01-05", Base: "EUR", Quote: "USD", Rate: 1.172},
+				{Date: "2026-01-02", Base: "EUR", Quote: "USD", Rate: 1.1734},
+			},
+		},
+		{
+			name:     "generic CSV",
+			importer: CSVImporter{},
+			feed:     "rate,quote,base,date\n151.2, jpy, usd, 2026-01-05\n",
+			want:     []fxrate.Rate{{Date: "2026-01-05", Base: "USD", Quote: "JPY", Rate: 151.2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.importer.ImportRatesFromReader(strings.NewReader(tt.feed))
+			if err != nil {
+				t.Fatalf("ImportRatesFromReader returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportRatesFromReader = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportersRejectInvalidFeeds(t *testing.T) {
+	tests := []struct {
+		name     string
+		importer importer
+		feed     string
+	}{
+		{"XML rate before a date", ECBXMLImporter{}, `<Cube><Cube currency="USD" rate="1.17"/></Cube>`},
+		{"CSV without a Date column", ECBCSVImporter{}, "USD,JPY\n1.17,183.42\n"},
+		{"CSV without a rate column", CSVImporter{}, "date,base,quote\n2026-01-05,USD,JPY\n"},
+		{"CSV with a negative rate", CSVImporter{}, "date,base,quote,rate\n2026-01-05,USD,JPY,-1\n"},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.importer.ImportRatesFromReader(strings.NewReader(tt.feed)); err == nil {
+			t.Errorf("%s: ImportRatesFromReader returned no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package fximport
+
+import (
+	"io"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+)
+
+type Importer interface {
+	ImportRatesFromReader(reader io.Reader) ([]fxrate.Rate, error)
+}
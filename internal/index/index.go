@@ -0,0 +1,30 @@
+package index
+
+import "github.com/turbak/bigmacindex/internal/domain/money"
+
+const DefaultBaseCountryCode = "US"
+
+// Index is the Big Mac index for a single date, valued against the base country's price.
+type Index struct {
+	Date            string
+	BaseCountryCode string
+	BaseCurrency    string
+	Entries         []Entry
+}
+
+type Entry struct {
+	CountryCode string
+	ProductName string
+	PriceDate   string
+	LocalPrice  money.Money
+	BasePrice   money.Money
+	// ExchangeRate is the number of local currency units per one unit of the base currency.
+	ExchangeRate float64
+	// DollarPrice is the local price converted to the base currency at ExchangeRate.
+	DollarPrice money.Money
+	// ImpliedPPP is the exchange rate that would make the local and base prices equal.
+	ImpliedPPP float64
+	// Valuation is the percent by which the local currency is over (positive) or under (negative) valued.
+	Valuation float64
+	Err       error
+}
@@ -0,0 +1,100 @@
+package index
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+)
+
+type LatestPricesLister interface {
+	ListLatestPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error)
+}
+
+type RateGetter interface {
+	GetRate(ctx context.Context, date, base, quote string) (fxrate.Rate, error)
+}
+
+type repository struct {
+	pricesLister LatestPricesLister
+	rateGetter   RateGetter
+}
+
+func NewRepository(pricesLister LatestPricesLister, rateGetter RateGetter) *repository {
+	return &repository{
+		pricesLister: pricesLister,
+		rateGetter:   rateGetter,
+	}
+}
+
+func (r *repository) GetIndex(ctx context.Context, date, baseCountryCode string) (Index, error) {
+	if baseCountryCode == "" {
+		baseCountryCode = DefaultBaseCountryCode
+	}
+
+	priceRecs, err := r.pricesLister.ListLatestPrices(ctx, price.Filter{To: date})
+	if err != nil {
+		return Index{}, fmt.Errorf("failed to list prices: %w", err)
+	}
+
+	basePrices := make(map[string]price.PriceRecord)
+	var defaultBase *price.PriceRecord
+	for i, priceRec := range priceRecs {
+		if priceRec.CountryCode != baseCountryCode {
+			continue
+		}
+		basePrices[priceRec.ProductName] = priceRec
+		if defaultBase == nil {
+			defaultBase = &priceRecs[i]
+		}
+	}
+	if defaultBase == nil {
+		return Index{}, fmt.Errorf("no price recorded for base country %s on or before %s", baseCountryCode, date)
+	}
+
+	idx := Index{
+		Date:            date,
+		BaseCountryCode: baseCountryCode,
+		BaseCurrency:    defaultBase.Price.Currency,
+	}
+
+	for _, priceRec := range priceRecs {
+		basePrice, ok := basePrices[priceRec.ProductName]
+		if !ok {
+			basePrice = *defaultBase
+		}
+
+		idx.Entries = append(idx.Entries, r.calculateEntry(ctx, date, priceRec, basePrice))
+	}
+
+	return idx, nil
+}
+
+func (r *repository) calculateEntry(ctx context.Context, date string, priceRec, basePrice price.PriceRecord) Entry {
+	entry := Entry{
+		CountryCode: priceRec.CountryCode,
+		ProductName: priceRec.ProductName,
+		PriceDate:   priceRec.CreatedDate,
+		LocalPrice:  priceRec.Price,
+		BasePrice:   basePrice.Price,
+	}
+
+	local, base := priceRec.Price, basePrice.Price
+	rate, err := r.rateGetter.GetRate(ctx, date, base.Currency, local.Currency)
+	if err != nil {
+		entry.Err = fmt.Errorf("no %s/%s exchange rate for %s: %w", base.Currency, local.Currency, date, err)
+		return entry
+	}
+	if rate.Rate == 0 || base.IsZero() {
+		entry.Err = fmt.Errorf("cannot value %s against a zero rate or base price", priceRec.CountryCode)
+		return entry
+	}
+
+	entry.ExchangeRate = rate.Rate
+	entry.DollarPrice = local.Convert(1/rate.Rate, base.Currency, base.MinorUnits)
+	entry.ImpliedPPP = local.Float64() / base.Float64()
+	entry.Valuation = (entry.ImpliedPPP/rate.Rate - 1) * 100
+
+	return entry
+}
@@ -0,0 +1,101 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+	"github.com/turbak/bigmacindex/internal/domain/money"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+)
+
+type fakePrices []price.PriceRecord
+
+func (f fakePrices) ListLatestPrices(context.Context, price.Filter) ([]price.PriceRecord, error) {
+	return f, nil
+}
+
+// fakeRates holds the number of quote units per USD.
+type fakeRates map[string]float64
+
+func (f fakeRates) GetRate(_ context.Context, date, base, quote string) (fxrate.Rate, error) {
+	rate, ok := f[quote]
+	if base != "USD" || !ok {
+		return fxrate.Rate{}, errors.New("not found")
+	}
+	return fxrate.Rate{Date: date, Base: base, Quote: quote, Rate: rate}, nil
+}
+
+func TestGetIndex(t *testing.T) {
+	prices := fakePrices{
+		{CountryCode: "US", ProductName: "Big Mac", CreatedDate: "2026-01-01", Price: money.New(569, "USD", 2)},
+		{CountryCode: "CH", ProductName: "Big Mac", CreatedDate: "2026-01-01", Price: money.New(710, "CHF", 2)},
+		{CountryCode: "JP", ProductName: "Big Mac", CreatedDate: "2025-12-30", Price: money.New(480, "JPY", 0)},
+		{CountryCode: "KW", ProductName: "Big Mac", CreatedDate: "2026-01-01", Price: money.New(1250, "KWD", 3)},
+		{CountryCode: "AR", ProductName: "Big Mac", CreatedDate: "2026-01-01", Price: money.New(700000, "ARS", 2)},
+	}
+	rates := fakeRates{"USD": 1, "CHF": 0.8, "JPY": 150, "KWD": 0.31}
+
+	tests := []struct {
+		country       string
+		wantPPP       float64
+		wantValuation float64
+		wantDollar    money.Money
+		wantErr       bool
+	}{
+		{country: "US", wantPPP: 1, wantValuation: 0, wantDollar: money.New(569, "USD", 2)},
+		// 7.10 CHF is 8.88 USD: overvalued.
+		{country: "CH", wantPPP: 1.2478, wantValuation: 55.98, wantDollar: money.New(888, "USD", 2)},
+		// 480 JPY is 3.20 USD: undervalued.
+		{country: "JP", wantPPP: 84.3585, wantValuation: -43.76, wantDollar: money.New(320, "USD", 2)},
+		{country: "KW", wantPPP: 0.2197, wantValuation: -29.13, wantDollar: money.New(403, "USD", 2)},
+		{country: "AR", wantErr: true},
+	}
+
+	idx, err := NewRepository(prices, rates).GetIndex(context.Background(), "2026-01-01", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.BaseCountryCode != "US" || idx.BaseCurrency != "USD" {
+		t.Errorf("base = %s %s, want US USD", idx.BaseCountryCode, idx.BaseCurrency)
+	}
+	if len(idx.Entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(idx.Entries), len(tests))
+	}
+
+	for i, tt := range tests {
+		entry := idx.Entries[i]
+		if entry.CountryCode != tt.country {
+			t.Errorf("entry %d is %s, want %s", i, entry.CountryCode, tt.country)
+			continue
+		}
+		if tt.wantErr {
+			if entry.Err == nil {
+				t.Errorf("%s: got no error for a missing rate", tt.country)
+			}
+			continue
+		}
+		if entry.Err != nil {
+			t.Errorf("%s: %v", tt.country, entry.Err)
+			continue
+		}
+		if math.Abs(entry.ImpliedPPP-tt.wantPPP) > 0.0001 {
+			t.Errorf("%s: implied PPP = %.4f, want %.4f", tt.country, entry.ImpliedPPP, tt.wantPPP)
+		}
+		if math.Abs(entry.Valuation-tt.wantValuation) > 0.01 {
+			t.Errorf("%s: valuation = %.2f, want %.2f", tt.country, entry.Valuation, tt.wantValuation)
+		}
+		if entry.DollarPrice != tt.wantDollar {
+			t.Errorf("%s: dollar price = %s, want %s", tt.country, entry.DollarPrice, tt.wantDollar)
+		}
+	}
+}
+
+func TestGetIndexWithoutBasePrice(t *testing.T) {
+	prices := fakePrices{{CountryCode: "CH", ProductName: "Big Mac", Price: money.New(710, "CHF", 2)}}
+	if _, err := NewRepository(prices, fakeRates{}).GetIndex(context.Background(), "2026-01-01", "US"); err == nil {
+		t.Error("GetIndex returned no error without a base country price")
+	}
+}
@@ -0,0 +1,149 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+)
+
+// circuitBreaker stops requests to a link, or to every link of a host, after repeated
+// consecutive failures. Once the cooldown passes a single trial request is let through:
+// success closes the circuit, another failure keeps it open for a further cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuit),
+	}
+}
+
+func (b *circuitBreaker) allow(linkID link.ID, host string) error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range breakerKeys(linkID, host) {
+		c, ok := b.circuits[key]
+		if !ok || c.failures < b.threshold {
+			continue
+		}
+		if now.Before(c.openUntil) {
+			return fmt.Errorf("circuit for %s is open until %s after %d consecutive failures", key, c.openUntil.Format(time.DateTime), c.failures)
+		}
+		// Half-open: reserve the trial request so concurrent callers keep seeing an open circuit.
+		c.openUntil = now.Add(b.cooldown)
+	}
+
+	return nil
+}
+
+func (b *circuitBreaker) recordSuccess(linkID link.ID, host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range breakerKeys(linkID, host) {
+		delete(b.circuits, key)
+	}
+}
+
+func (b *circuitBreaker) recordFailure(linkID link.ID, host string) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, key := range breakerKeys(linkID, host) {
+		c, ok := b.circuits[key]
+		if !ok {
+			c = &circuit{}
+			b.circuits[key] = c
+		}
+		c.failures++
+		if c.failures >= b.threshold {
+			c.openUntil = time.Now().Add(b.cooldown)
+		}
+	}
+}
+
+// restore replaces the circuits with the ones attempts lead to, as if this breaker had recorded them.
+// Attempts must be newest first; a link's attempt counts for its host as well as for the link.
+func (b *circuitBreaker) restore(attempts []pollrun.Attempt) {
+	circuits := make(map[string]*circuit)
+	closed := make(map[string]bool)
+	for _, attempt := range attempts {
+		var host string
+		if u, err := url.Parse(attempt.URL); err == nil {
+			host = hostname(u)
+		}
+
+		for _, key := range breakerKeys(attempt.LinkID, host) {
+			if closed[key] {
+				continue
+			}
+			if attempt.ErrorKind != string(FailureKindHTTP) {
+				closed[key] = true
+				continue
+			}
+
+			c, ok := circuits[key]
+			if !ok {
+				// The newest failure is the one that (re)opened the circuit.
+				c = &circuit{openUntil: attempt.CreatedAt.Add(b.cooldown)}
+				circuits[key] = c
+			}
+			c.failures++
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.circuits = circuits
+}
+
+// restoreBreaker restores the circuit breakers from the history of past runs, if the poller has one.
+// Counting up to the threshold is enough to tell whether a circuit is open.
+func (p *poller) restoreBreaker(ctx context.Context) error {
+	if p.history == nil || p.breaker.threshold <= 0 {
+		return nil
+	}
+
+	// Attempts that made no request or were cut short, such as those skipped by an open circuit,
+	// neither open nor close one.
+	attempts, err := p.history.ListLatestAttempts(ctx, p.breaker.threshold,
+		string(FailureKindCircuitOpen), string(FailureKindConfig), string(FailureKindCanceled))
+	if err != nil {
+		return err
+	}
+
+	p.breaker.restore(attempts)
+	return nil
+}
+
+func breakerKeys(linkID link.ID, host string) []string {
+	return []string{fmt.Sprintf("link #%d", linkID), "host " + host}
+}
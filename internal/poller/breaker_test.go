@@ -0,0 +1,96 @@
+package poller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+)
+
+func TestCircuitBreakerRestore(t *testing.T) {
+	now := time.Now()
+	failure := func(linkID link.ID, url string, age time.Duration) pollrun.Attempt {
+		return pollrun.Attempt{LinkID: linkID, URL: url, ErrorKind: string(FailureKindHTTP), CreatedAt: now.Add(-age)}
+	}
+
+	tests := []struct {
+		name     string
+		attempts []pollrun.Attempt
+		linkID   link.ID
+		host     string
+		wantOpen bool
+	}{
+		{
+			name:     "failures up to the threshold open the link",
+			attempts: []pollrun.Attempt{failure(1, "https://a.example/x", time.Minute), failure(1, "https://a.example/x", 2*time.Minute)},
+			linkID:   1,
+			host:     "b.example",
+			wantOpen: true,
+		},
+		{
+			name:     "failures of several links open the host, whatever its case",
+			attempts: []pollrun.Attempt{failure(1, "https://Shop.Example/x", time.Minute), failure(2, "https://SHOP.example/y", 2*time.Minute)},
+			linkID:   3,
+			host:     "shop.example",
+			wantOpen: true,
+		},
+		{
+			name: "a newer success closes the circuit",
+			attempts: []pollrun.Attempt{
+				{LinkID: 1, URL: "https://a.example/x", CreatedAt: now},
+				failure(1, "https://a.example/x", time.Minute), failure(1, "https://a.example/x", 2*time.Minute),
+			},
+			linkID: 1,
+			host:   "a.example",
+		},
+		{
+			name:     "the cooldown has passed",
+			attempts: []pollrun.Attempt{failure(1, "https://a.example/x", 2*time.Hour), failure(1, "https://a.example/x", 3*time.Hour)},
+			linkID:   1,
+			host:     "a.example",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(2, time.Hour)
+			b.restore(tt.attempts)
+			if err := b.allow(tt.linkID, tt.host); (err != nil) != tt.wantOpen {
+				t.Errorf("allow() = %v, want open %v", err, tt.wantOpen)
+			}
+		})
+	}
+}
+
+func TestFetchCanceledKeepsCircuitClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	p := &poller{
+		httpClient:  server.Client(),
+		hostLimiter: newHostLimiter(1, 0),
+		breaker:     newCircuitBreaker(1, time.Hour),
+		retry:       RetryConfig{MaxAttempts: 1},
+	}
+	prepared, err := prepareRequest(requestSpec{URL: server.URL}, requestData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	outcome := &LinkOutcome{Link: link.LinkDescription{ID: 1}}
+	_, linkErr := p.fetch(ctx, outcome, prepared)
+	if linkErr == nil || linkErr.Kind != FailureKindCanceled {
+		t.Fatalf("fetch() error = %v, want a %s error", linkErr, FailureKindCanceled)
+	}
+	if err := p.breaker.allow(1, prepared.hostname()); err != nil {
+		t.Errorf("allow() after a canceled fetch = %v, want a closed circuit", err)
+	}
+}
@@ -0,0 +1,29 @@
+package poller
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// decodeBody converts body to UTF-8 based on its byte order mark, the Content-Type charset or a
+// <meta charset> declaration. A charset that is only guessed at is ignored when body is already
+// valid UTF-8, since plenty of pages declare one encoding and serve another.
+func decodeBody(body []byte, contentType string) ([]byte, error) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(body)) {
+		return bytes.TrimPrefix(body, utf8BOM), nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s body: %w", name, err)
+	}
+
+	// The UTF-16 decoders keep the byte order mark, as U+FEFF.
+	return bytes.TrimPrefix(decoded, utf8BOM), nil
+}
@@ -0,0 +1,33 @@
+package poller
+
+import "testing"
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{"UTF-8", "Big Mac 5,69 €", "text/html; charset=utf-8", "Big Mac 5,69 €"},
+		{"charset in the header", "\xc1\xe8\xe3 \xcc\xe0\xea 249", "text/html; charset=windows-1251", "Биг Мак 249"},
+		{"charset in a meta tag", `<meta charset="shift_jis"><p>390` + "\x89\x7e", "text/html", `<meta charset="shift_jis"><p>390円`},
+		{"UTF-8 byte order mark", "\xef\xbb\xbf<p>5.69 €", "text/html", "<p>5.69 €"},
+		{"UTF-16 byte order mark", "\xff\xfe5\x00.\x006\x009\x00", "text/html", "5.69"},
+		{"meta tag contradicted by valid UTF-8", `<meta charset="iso-8859-1"><p>5,69 €`, "text/html", `<meta charset="iso-8859-1"><p>5,69 €`},
+		{"undeclared invalid UTF-8 falls back to windows-1252", "<p>\xa35.69", "text/html", "<p>£5.69"},
+		{"JSON without a charset", `{"price": "249 ₽"}`, "application/json", `{"price": "249 ₽"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeBody([]byte(tt.body), tt.contentType)
+			if err != nil {
+				t.Fatalf("decodeBody() returned error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("decodeBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
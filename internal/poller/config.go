@@ -0,0 +1,54 @@
+package poller
+
+import "time"
+
+type Config struct {
+	// Workers is the number of links polled concurrently across all hosts.
+	Workers int
+	// MaxConcurrentPerHost caps the in-flight requests to a single host.
+	MaxConcurrentPerHost int
+	// MinIntervalPerHost is the minimum delay between the starts of two requests to the same host.
+	MinIntervalPerHost time.Duration
+	RequestTimeout     time.Duration
+	Retry              RetryConfig
+	Breaker            BreakerConfig
+	// Archiver, when set, stores every response received, including those of steps, retries and errors.
+	Archiver ResponseArchiver
+	// History, when set, restores the circuit breakers from the attempts of past runs before every run,
+	// so circuits hold across processes rather than only within a daemon.
+	History AttemptHistory
+}
+
+// RetryConfig controls how network errors and retryable statuses (408, 429, 5xx) are retried.
+// Attempts back off exponentially from BaseDelay up to MaxDelay; a Retry-After longer than
+// MaxDelay fails the link instead of waiting.
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// BreakerConfig opens the circuit of a link, and of its host, after FailureThreshold consecutive
+// failed fetches; no requests are made to it until Cooldown passes. A zero threshold disables it.
+type BreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Workers:              8,
+		MaxConcurrentPerHost: 1,
+		MinIntervalPerHost:   2 * time.Second,
+		RequestTimeout:       30 * time.Second,
+		Retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseDelay:   time.Second,
+			MaxDelay:    30 * time.Second,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			Cooldown:         30 * time.Minute,
+		},
+	}
+}
@@ -0,0 +1,195 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+)
+
+type response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// fetch sends req for outcome.Link, retrying network errors and retryable statuses with
+// exponential backoff until the attempts run out or the circuit breaker opens.
+func (p *poller) fetch(ctx context.Context, outcome *LinkOutcome, req preparedRequest) (response, *LinkError) {
+	linkDesc := outcome.Link
+	host := req.hostname()
+
+	for attempt := 1; ; attempt++ {
+		if err := p.breaker.allow(linkDesc.ID, host); err != nil {
+			return response{}, newLinkError(FailureKindCircuitOpen, err)
+		}
+
+		resp, retryAfter, linkErr := p.fetchOnce(ctx, outcome, req)
+		if linkErr == nil {
+			p.breaker.recordSuccess(linkDesc.ID, host)
+			return resp, nil
+		}
+		// A request cut short by stopping the run says nothing about the host.
+		if ctx.Err() != nil {
+			return resp, newLinkError(FailureKindCanceled, linkErr.Err)
+		}
+
+		retryable := linkErr.Kind == FailureKindHTTP && isRetryable(resp.StatusCode)
+		if !retryable {
+			if linkErr.Kind == FailureKindHTTP {
+				p.breaker.recordFailure(linkDesc.ID, host)
+			}
+			return resp, linkErr
+		}
+
+		delay := p.retryDelay(attempt, retryAfter)
+		if attempt >= p.retry.MaxAttempts || delay < 0 {
+			p.breaker.recordFailure(linkDesc.ID, host)
+			if attempt > 1 {
+				linkErr.Err = fmt.Errorf("giving up after %d attempts: %w", attempt, linkErr.Err)
+			}
+			return resp, linkErr
+		}
+
+		log.Printf("Retrying link #%d in %s after attempt %d failed: %v", linkDesc.ID, delay.Round(time.Millisecond), attempt, linkErr)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, newLinkError(FailureKindCanceled, ctx.Err())
+		}
+	}
+}
+
+// fetchOnce performs a single request within the host's limits. On failure it also returns
+// the delay requested by the server's Retry-After header, if any.
+func (p *poller) fetchOnce(ctx context.Context, outcome *LinkOutcome, prepared preparedRequest) (response, time.Duration, *LinkError) {
+	release, err := p.hostLimiter.acquire(ctx, prepared.hostname())
+	if err != nil {
+		return response{}, 0, newLinkError(FailureKindHTTP, err)
+	}
+	defer release()
+
+	// The timeout covers the request and reading its body, not the wait for the host limiter.
+	timeout := p.requestTimeout
+	if prepared.timeout > 0 {
+		timeout = prepared.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := prepared.newHTTPRequest(ctx)
+	if err != nil {
+		return response{}, 0, newLinkError(FailureKindConfig, err)
+	}
+
+	start := time.Now()
+	defer func() { outcome.Latency = time.Since(start) }()
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return response{}, 0, newLinkError(FailureKindHTTP, redactURLError(err, prepared.publicURL))
+	}
+	defer resp.Body.Close()
+
+	outcome.HTTPStatus = resp.StatusCode
+	// Error pages are read and archived too, they often explain why a price is missing.
+	body, err := io.ReadAll(resp.Body)
+	p.archiveResponse(outcome, prepared, resp, body, start)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return response{StatusCode: resp.StatusCode, Header: resp.Header}, parseRetryAfter(resp.Header.Get("Retry-After")),
+			newLinkError(FailureKindHTTP, fmt.Errorf("unexpected status %s", resp.Status))
+	}
+	if err != nil {
+		return response{StatusCode: resp.StatusCode, Header: resp.Header}, 0, newLinkError(FailureKindHTTP, fmt.Errorf("failed to read body: %w", err))
+	}
+
+	return response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, 0, nil
+}
+
+// archiveResponse stores a received response and records its key on outcome. Archiving is
+// best effort: a failure is logged and doesn't fail the link.
+func (p *poller) archiveResponse(outcome *LinkOutcome, prepared preparedRequest, resp *http.Response, body []byte, receivedAt time.Time) {
+	if p.archiver == nil {
+		return
+	}
+
+	// The public URL keeps environment variables, e.g. API keys, out of the archive and its keys.
+	key, err := p.archiver.Put(archive.Record{
+		TargetURI:  prepared.publicURL,
+		Date:       receivedAt,
+		Proto:      resp.Proto,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+	if err != nil {
+		log.Printf("Failed to archive response of link #%d from %s: %v", outcome.Link.ID, prepared.publicURL, err)
+		return
+	}
+
+	outcome.ArchiveKeys = append(outcome.ArchiveKeys, key)
+}
+
+// retryDelay returns the backoff before the next attempt, or a negative duration when the
+// server asked us to wait longer than we're willing to.
+func (p *poller) retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.retry.MaxDelay {
+		delay = p.retry.MaxDelay
+	}
+	// Equal jitter: keep half of the delay and randomize the rest so retries don't synchronize.
+	delay = delay/2 + rand.N(delay/2+1)
+
+	if retryAfter > 0 {
+		if retryAfter > p.retry.MaxDelay {
+			return -1
+		}
+		delay = max(delay, retryAfter)
+	}
+
+	return delay
+}
+
+// isRetryable reports whether a failed request may succeed if repeated. A zero status means
+// no response was received at all, e.g. a connection reset or timeout.
+func isRetryable(statusCode int) bool {
+	switch {
+	case statusCode == 0:
+		return true
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	case statusCode >= http.StatusInternalServerError:
+		return statusCode != http.StatusNotImplemented
+	default:
+		return false
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0)
+	}
+
+	return 0
+}
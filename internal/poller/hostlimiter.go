@@ -0,0 +1,78 @@
+package poller
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// hostLimiter bounds how many requests run against a host at once and how often new ones may start.
+type hostLimiter struct {
+	maxConcurrent int
+	minInterval   time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	slots chan struct{}
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+func newHostLimiter(maxConcurrent int, minInterval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		maxConcurrent: max(maxConcurrent, 1),
+		minInterval:   minInterval,
+		hosts:         make(map[string]*hostState),
+	}
+}
+
+// acquire blocks until a request to host may start. The returned func must be called once it is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	state := l.state(host)
+
+	select {
+	case state.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-state.slots }
+
+	state.mu.Lock()
+	now := time.Now()
+	start := state.nextStart
+	if start.Before(now) {
+		start = now
+	}
+	state.nextStart = start.Add(l.minInterval)
+	state.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+func (l *hostLimiter) state(host string) *hostState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{slots: make(chan struct{}, l.maxConcurrent)}
+		l.hosts[host] = state
+	}
+	return state
+}
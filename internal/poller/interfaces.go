@@ -1,9 +1,21 @@
 package poller
 
 import (
+	"context"
 	"io"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
 )
 
 type Parser interface {
 	ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error)
 }
+
+type ResponseArchiver interface {
+	Put(rec archive.Record) (string, error)
+}
+
+type AttemptHistory interface {
+	ListLatestAttempts(ctx context.Context, perLink int, skipErrorKinds ...string) ([]pollrun.Attempt, error)
+}
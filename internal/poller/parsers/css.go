@@ -0,0 +1,50 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+)
+
+// attributeSuffix matches a trailing "@attr" that extracts an attribute instead of the element's text.
+var attributeSuffix = regexp.MustCompile(`^(.*\S)@([A-Za-z_:][-A-Za-z0-9_:.]*)$`)
+
+// CSSParser reads the text of the first element matching a CSS selector, or one of its attributes
+// when the selector ends with "@attr", e.g. "meta[itemprop=price]@content".
+type CSSParser struct{}
+
+func (p CSSParser) ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error) {
+	selector, attr := priceSelector, ""
+	if m := attributeSuffix.FindStringSubmatch(priceSelector); m != nil {
+		selector, attr = m[1], m[2]
+	}
+
+	sel, err := cascadia.Parse(selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid CSS selector '%s': %w", selector, err)
+	}
+
+	doc, err := htmlquery.Parse(reader)
+	if err != nil {
+		return "", err
+	}
+
+	node := cascadia.Query(doc, sel)
+	if node == nil {
+		return "", fmt.Errorf("%w for CSS selector '%s'", ErrPriceNotFound, selector)
+	}
+	if attr == "" {
+		return htmlquery.InnerText(node), nil
+	}
+
+	for _, a := range node.Attr {
+		if a.Key == attr {
+			return a.Val, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: element matching CSS selector '%s' has no attribute '%s'", ErrPriceNotFound, selector, attr)
+}
@@ -0,0 +1,26 @@
+package parsers
+
+import "testing"
+
+const cssPage = `<html><body>
+<div id="product" class="product">
+	<meta itemprop="price" content="5.69">
+	<span class="price old">6,49 €</span>
+	<span class="price">5,69 €</span>
+	<button data-price="569" data-currency="EUR">Buy</button>
+</div>
+</body></html>`
+
+func TestCSSParser(t *testing.T) {
+	testParser(t, CSSParser{}, cssPage, []parserTest{
+		{name: "element text", selector: "span.price:not(.old)", want: "5,69 €"},
+		{name: "first match", selector: ".price", want: "6,49 €"},
+		{name: "combinators", selector: "#product > span:last-of-type", want: "5,69 €"},
+		{name: "attribute", selector: "meta[itemprop=price]@content", want: "5.69"},
+		{name: "data attribute", selector: "button[data-currency]@data-price", want: "569"},
+		{name: "no match", selector: ".missing", wantErr: ErrPriceNotFound},
+		{name: "missing attribute", selector: "span.price@content", wantErr: ErrPriceNotFound},
+	})
+
+	testInvalidSelectors(t, CSSParser{}, cssPage, "span[")
+}
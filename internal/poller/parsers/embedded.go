@@ -0,0 +1,72 @@
+package parsers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// EmbeddedJSONParser reads a price from JSON embedded in an HTML page, such as a Next.js
+// __NEXT_DATA__ script or a `window.__INITIAL_STATE__ = {...};` assignment.
+// Its selector is "locator | jsonpath", where the locator is an XPath expression when it starts
+// with "/" or "(" and a CSS selector otherwise, e.g. "script#__NEXT_DATA__ | $.props.price".
+type EmbeddedJSONParser struct{}
+
+func (p EmbeddedJSONParser) ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error) {
+	sep := strings.LastIndex(priceSelector, "|")
+	if sep < 0 || !strings.HasPrefix(strings.TrimSpace(priceSelector[sep+1:]), "$") {
+		return "", fmt.Errorf("embedded JSON selector '%s' must look like 'locator | $.json.path'", priceSelector)
+	}
+	locator, jsonPath := strings.TrimSpace(priceSelector[:sep]), strings.TrimSpace(priceSelector[sep+1:])
+
+	doc, err := htmlquery.Parse(reader)
+	if err != nil {
+		return "", err
+	}
+
+	node, err := locate(doc, locator)
+	if err != nil {
+		return "", err
+	}
+	if node == nil {
+		return "", fmt.Errorf("%w: nothing matches locator '%s'", ErrPriceNotFound, locator)
+	}
+
+	return JSONParser{}.ParsePriceStringFromReader(strings.NewReader(UnwrapJSON(htmlquery.InnerText(node))), jsonPath)
+}
+
+func locate(doc *html.Node, locator string) (*html.Node, error) {
+	if strings.HasPrefix(locator, "/") || strings.HasPrefix(locator, "(") {
+		node, err := htmlquery.Query(doc, locator)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XPath '%s': %w", locator, err)
+		}
+		return node, nil
+	}
+
+	sel, err := cascadia.Parse(locator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSS selector '%s': %w", locator, err)
+	}
+	return cascadia.Query(doc, sel), nil
+}
+
+// UnwrapJSON strips a JavaScript assignment such as `window.__STATE__ = ` in front of a JSON value.
+// Anything after the value, like a trailing semicolon, is left for the JSON decoder to ignore.
+func UnwrapJSON(script string) string {
+	script = strings.TrimSpace(script)
+	if !strings.HasPrefix(script, "{") && !strings.HasPrefix(script, "[") {
+		if eq := strings.Index(script, "="); eq >= 0 {
+			script = script[eq+1:]
+		}
+	}
+	if start := strings.IndexAny(script, "{["); start >= 0 {
+		script = script[start:]
+	}
+
+	return script
+}
@@ -0,0 +1,41 @@
+package parsers
+
+import "testing"
+
+const embeddedPage = `<html><head>
+<script id="__NEXT_DATA__" type="application/json">{"props": {"pageProps": {"product": {"price": {"amount": "5.69", "currency": "USD"}}}}}</script>
+<script>window.__INITIAL_STATE__ = {"menu": {"items": [{"name": "Big Mac", "price": 249}]}};</script>
+<script type="application/json">["first"]</script>
+<script type="application/json">{"price": "4,79"}</script>
+</head><body></body></html>`
+
+func TestEmbeddedJSONParser(t *testing.T) {
+	testParser(t, EmbeddedJSONParser{}, embeddedPage, []parserTest{
+		{name: "CSS locator", selector: "script#__NEXT_DATA__ | $.props.pageProps.product.price.amount", want: "5.69"},
+		{name: "money object", selector: "script#__NEXT_DATA__ | $.props.pageProps.product.price", want: "5.69 USD"},
+		{name: "JavaScript assignment", selector: "//script[contains(., '__INITIAL_STATE__')] | $.menu.items[0].price", want: "249"},
+		{name: "indexed XPath locator", selector: "(//script[@type='application/json'])[3] | $.price", want: "4,79"},
+		{name: "locator with a pipe", selector: "//script[contains(., 'a|b')] | $.price", wantErr: ErrPriceNotFound},
+		{name: "nothing located", selector: "script#missing | $.price", wantErr: ErrPriceNotFound},
+		{name: "path not found", selector: "script#__NEXT_DATA__ | $.props.missing", wantErr: ErrPriceNotFound},
+	})
+
+	testInvalidSelectors(t, EmbeddedJSONParser{}, embeddedPage, "script#__NEXT_DATA__", "script | price", "//script[ | $.price")
+}
+
+func TestUnwrapJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`  [1, 2]  `, `[1, 2]`},
+		{`window.__STATE__ = {"a": 1};`, `{"a": 1};`},
+		{`{"a": "x=y"}`, `{"a": "x=y"}`},
+	}
+
+	for _, tt := range tests {
+		if got := UnwrapJSON(tt.in); got != tt.want {
+			t.Errorf("UnwrapJSON(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
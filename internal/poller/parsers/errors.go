@@ -0,0 +1,6 @@
+package parsers
+
+import "errors"
+
+// ErrPriceNotFound is returned when a selector is valid but matches nothing in the document.
+var ErrPriceNotFound = errors.New("no price found")
@@ -15,16 +15,12 @@ func (p HTMLParser) ParsePriceStringFromReader(reader io.Reader, priceSelector s
 		return "", err
 	}
 
-	res, err := io.ReadAll(reader)
+	node, err := htmlquery.Query(doc, priceSelector)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("invalid XPath '%s': %w", priceSelector, err)
 	}
-
-	_ = res
-
-	node := htmlquery.FindOne(doc, priceSelector)
 	if node == nil {
-		return "", fmt.Errorf("no price found for XPath '%s'", priceSelector)
+		return "", fmt.Errorf("%w for XPath '%s'", ErrPriceNotFound, priceSelector)
 	}
 	priceStr := htmlquery.InnerText(node)
 	return priceStr, nil
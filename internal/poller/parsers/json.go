@@ -4,10 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/oliveagle/jsonpath"
 )
 
+// amountKeys and currencyKeys are the fields read from a JSONPath result that is a money object,
+// e.g. {"amount": 4.99, "currency": "USD"}. Keys are matched case-insensitively.
+var (
+	amountKeys   = []string{"amount", "value", "price"}
+	currencyKeys = []string{"currency", "currencycode", "currency_code", "pricecurrency"}
+)
+
+// JSONParser reads the value a JSONPath selects. Numbers keep their digits as written, without
+// trailing zeros after the decimal point, a single-element array yields its element, and a money
+// object yields its amount followed by its currency, e.g. "4.99 USD".
 type JSONParser struct{}
 
 func (p JSONParser) ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error) {
@@ -17,19 +28,93 @@ func (p JSONParser) ParsePriceStringFromReader(reader io.Reader, priceSelector s
 	}
 
 	var data any
-	err = json.NewDecoder(reader).Decode(&data)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	err = decoder.Decode(&data)
 	if err != nil {
 		return "", err
 	}
+	// Filters such as [?(@.price > 5)] compare numeric strings as numbers, but not json.Number.
+	data = numbersToText(data)
 
 	result, err := path.Lookup(data)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w for JSONPath '%s': %w", ErrPriceNotFound, priceSelector, err)
 	}
 
-	if str, ok := result.(string); ok {
-		return str, nil
+	priceStr, err := jsonPriceString(result)
+	if err != nil {
+		return "", fmt.Errorf("JSONPath '%s': %w", priceSelector, err)
 	}
+	return priceStr, nil
+}
 
-	return "", fmt.Errorf("expected a string but got %v of type %T", result, result)
+func jsonPriceString(result any) (string, error) {
+	switch v := result.(type) {
+	case string:
+		return v, nil
+	case []any:
+		switch len(v) {
+		case 0:
+			return "", ErrPriceNotFound
+		case 1:
+			return jsonPriceString(v[0])
+		}
+		return "", fmt.Errorf("expected a single price but matched %d values", len(v))
+	case map[string]any:
+		amount, ok := lookupKey(v, amountKeys)
+		if !ok {
+			return "", fmt.Errorf("expected a price but got an object without any of the fields %v", amountKeys)
+		}
+		amountStr, err := jsonPriceString(amount)
+		if err != nil {
+			return "", err
+		}
+
+		if currency, ok := lookupKey(v, currencyKeys); ok {
+			if code, ok := currency.(string); ok && code != "" {
+				return amountStr + " " + code, nil
+			}
+		}
+		return amountStr, nil
+	}
+
+	return "", fmt.Errorf("expected a price but got %v of type %T", result, result)
+}
+
+func numbersToText(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		return numberText(v)
+	case []any:
+		for i, elem := range v {
+			v[i] = numbersToText(elem)
+		}
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = numbersToText(elem)
+		}
+	}
+	return value
+}
+
+// numberText returns n as written, dropping trailing zeros after the decimal point: "5.690" would
+// otherwise read as five thousand six hundred ninety.
+func numberText(n json.Number) string {
+	text := n.String()
+	if !strings.Contains(text, ".") || strings.ContainsAny(text, "eE") {
+		return text
+	}
+	return strings.TrimSuffix(strings.TrimRight(text, "0"), ".")
+}
+
+func lookupKey(obj map[string]any, keys []string) (any, bool) {
+	for _, key := range keys {
+		for k, v := range obj {
+			if strings.EqualFold(k, key) {
+				return v, true
+			}
+		}
+	}
+	return nil, false
 }
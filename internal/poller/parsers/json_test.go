@@ -0,0 +1,45 @@
+package parsers
+
+import "testing"
+
+const jsonDoc = `{
+	"price": "5.69",
+	"amount": 0.1,
+	"total": 129900,
+	"precise": 5.690,
+	"large": 12345678901234567890,
+	"digits": 1234567.8912345678,
+	"exponent": 1.5e3,
+	"single": [4.79],
+	"empty": [],
+	"money": {"Amount": 4.99, "CurrencyCode": "USD"},
+	"moneyWithoutCurrency": {"value": "3,50"},
+	"items": [
+		{"name": "Big Mac", "price": 10, "size": "M"},
+		{"name": "Cheeseburger", "price": 3, "size": "S"}
+	]
+}`
+
+func TestJSONParser(t *testing.T) {
+	testParser(t, JSONParser{}, jsonDoc, []parserTest{
+		{name: "string", selector: "$.price", want: "5.69"},
+		{name: "number keeps its decimals", selector: "$.amount", want: "0.1"},
+		{name: "integer", selector: "$.total", want: "129900"},
+		{name: "trailing zero", selector: "$.precise", want: "5.69"},
+		{name: "large integer", selector: "$.large", want: "12345678901234567890"},
+		{name: "more digits than a float64 holds", selector: "$.digits", want: "1234567.8912345678"},
+		{name: "exponent", selector: "$.exponent", want: "1.5e3"},
+		{name: "single-element array", selector: "$.single", want: "4.79"},
+		{name: "money object", selector: "$.money", want: "4.99 USD"},
+		{name: "money object without currency", selector: "$.moneyWithoutCurrency", want: "3,50"},
+		{name: "index", selector: "$.items[1].price", want: "3"},
+		{name: "string filter", selector: "$.items[?(@.name == 'Big Mac')].price", want: "10"},
+		{name: "greater-than filter", selector: "$.items[?(@.price > 5)].price", want: "10"},
+		{name: "less-than filter", selector: "$.items[?(@.price < 5)].name", want: "Cheeseburger"},
+		{name: "empty array", selector: "$.empty", wantErr: ErrPriceNotFound},
+		{name: "filter matching nothing", selector: "$.items[?(@.price > 50)].price", wantErr: ErrPriceNotFound},
+		{name: "missing key", selector: "$.missing", wantErr: ErrPriceNotFound},
+	})
+
+	testInvalidSelectors(t, JSONParser{}, jsonDoc, "$.items[*].price", "$.items[?(@.price > 1)].price", "$.items")
+}
@@ -0,0 +1,55 @@
+package parsers
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type priceParser interface {
+	ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error)
+}
+
+// parserTest is a selector and the price string, or error, a parser should read with it.
+type parserTest struct {
+	name     string
+	page     string // overrides the page passed to testParser
+	selector string
+	want     string
+	wantErr  error
+}
+
+func testParser(t *testing.T, parser priceParser, page string, tests []parserTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := page
+			if tt.page != "" {
+				body = tt.page
+			}
+			got, err := parser.ParsePriceStringFromReader(strings.NewReader(body), tt.selector)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParsePriceStringFromReader(%q) error = %v, want %v", tt.selector, err, tt.wantErr)
+				}
+			case err != nil || got != tt.want:
+				t.Errorf("ParsePriceStringFromReader(%q) = %q, %v, want %q", tt.selector, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// testInvalidSelectors checks that every selector is rejected, rather than found to match nothing.
+func testInvalidSelectors(t *testing.T, parser priceParser, page string, selectors ...string) {
+	t.Helper()
+
+	for _, selector := range selectors {
+		_, err := parser.ParsePriceStringFromReader(strings.NewReader(page), selector)
+		if err == nil || errors.Is(err, ErrPriceNotFound) {
+			t.Errorf("ParsePriceStringFromReader(%q) returned %v, want an invalid selector error", selector, err)
+		}
+	}
+}
@@ -20,9 +20,12 @@ func (p RegexParser) ParsePriceStringFromReader(reader io.Reader, priceSelector
 	}
 
 	matches := re.FindSubmatch(data)
-	if len(matches) >= 1 {
+	switch {
+	case len(matches) >= 2:
 		return string(matches[1]), nil
+	case len(matches) == 1:
+		return string(matches[0]), nil
 	}
 
-	return "", fmt.Errorf("no price found matching regex '%s'", priceSelector)
+	return "", fmt.Errorf("%w matching regex '%s'", ErrPriceNotFound, priceSelector)
 }
@@ -0,0 +1,207 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// StructuredDataParser reads a schema.org Offer price from JSON-LD, microdata or OpenGraph product tags,
+// in that order of preference. A non-empty selector picks the first offer whose product name contains it.
+// The currency, when given, is appended as an ISO code, e.g. "4.99 USD".
+type StructuredDataParser struct{}
+
+type offer struct {
+	productName string
+	price       string
+	currency    string
+}
+
+func (p StructuredDataParser) ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error) {
+	doc, err := htmlquery.Parse(reader)
+	if err != nil {
+		return "", err
+	}
+
+	offers := jsonLDOffers(doc)
+	offers = append(offers, microdataOffers(doc)...)
+	offers = append(offers, openGraphOffers(doc)...)
+
+	productName := strings.ToLower(strings.TrimSpace(priceSelector))
+	for _, o := range offers {
+		if productName != "" && !strings.Contains(strings.ToLower(o.productName), productName) {
+			continue
+		}
+		if o.currency == "" {
+			return o.price, nil
+		}
+		return o.price + " " + o.currency, nil
+	}
+
+	if productName != "" {
+		return "", fmt.Errorf("%w in structured data for product '%s' among %d offers", ErrPriceNotFound, priceSelector, len(offers))
+	}
+	return "", fmt.Errorf("%w in structured data", ErrPriceNotFound)
+}
+
+func jsonLDOffers(doc *html.Node) []offer {
+	var offers []offer
+	for _, script := range htmlquery.Find(doc, `//script[contains(@type, "ld+json")]`) {
+		decoder := json.NewDecoder(strings.NewReader(htmlquery.InnerText(script)))
+		decoder.UseNumber()
+
+		var data any
+		// Broken blocks are common and shouldn't hide the valid ones.
+		if err := decoder.Decode(&data); err != nil {
+			continue
+		}
+		walkJSONLD(data, "", &offers)
+	}
+
+	return offers
+}
+
+func walkJSONLD(value any, productName string, offers *[]offer) {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			walkJSONLD(item, productName, offers)
+		}
+	case map[string]any:
+		if hasSchemaType(v, "Product") {
+			productName = jsonLDString(v["name"])
+		}
+		if hasSchemaType(v, "Offer") || hasSchemaType(v, "AggregateOffer") {
+			if o, ok := jsonLDOffer(v, productName); ok {
+				*offers = append(*offers, o)
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			walkJSONLD(v[key], productName, offers)
+		}
+	}
+}
+
+func jsonLDOffer(v map[string]any, productName string) (offer, bool) {
+	o := offer{
+		productName: productName,
+		price:       jsonLDString(v["price"]),
+		currency:    jsonLDString(v["priceCurrency"]),
+	}
+	if o.price == "" {
+		o.price = jsonLDString(v["lowPrice"])
+	}
+
+	spec := v["priceSpecification"]
+	if specs, ok := spec.([]any); ok && len(specs) > 0 {
+		spec = specs[0]
+	}
+	if spec, ok := spec.(map[string]any); ok {
+		if o.price == "" {
+			o.price = jsonLDString(spec["price"])
+		}
+		if o.currency == "" {
+			o.currency = jsonLDString(spec["priceCurrency"])
+		}
+	}
+
+	return o, o.price != ""
+}
+
+func jsonLDString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// hasSchemaType reports whether a JSON-LD node's @type is schemaType, with or without a vocabulary prefix.
+func hasSchemaType(v map[string]any, schemaType string) bool {
+	types, ok := v["@type"].([]any)
+	if !ok {
+		types = []any{v["@type"]}
+	}
+
+	for _, t := range types {
+		name, _ := t.(string)
+		if idx := strings.LastIndexAny(name, "/:"); idx >= 0 {
+			name = name[idx+1:]
+		}
+		if name == schemaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func microdataOffers(doc *html.Node) []offer {
+	var offers []offer
+	for _, priceNode := range htmlquery.Find(doc, `//*[@itemprop="price" or @itemprop="lowPrice"]`) {
+		o := offer{price: itempropValue(priceNode)}
+		if o.price == "" {
+			continue
+		}
+
+		if scope := htmlquery.FindOne(priceNode, `ancestor::*[@itemscope][1]`); scope != nil {
+			if currencyNode := htmlquery.FindOne(scope, `.//*[@itemprop="priceCurrency"]`); currencyNode != nil {
+				o.currency = itempropValue(currencyNode)
+			}
+		}
+		if product := htmlquery.FindOne(priceNode, `ancestor::*[@itemscope][contains(@itemtype, "schema.org/Product")][1]`); product != nil {
+			if nameNode := htmlquery.FindOne(product, `.//*[@itemprop="name"]`); nameNode != nil {
+				o.productName = itempropValue(nameNode)
+			}
+		}
+
+		offers = append(offers, o)
+	}
+
+	return offers
+}
+
+// itempropValue returns a microdata property's machine-readable value, falling back to its text.
+func itempropValue(node *html.Node) string {
+	for _, attr := range []string{"content", "value"} {
+		if htmlquery.ExistsAttr(node, attr) {
+			return strings.TrimSpace(htmlquery.SelectAttr(node, attr))
+		}
+	}
+	return strings.TrimSpace(htmlquery.InnerText(node))
+}
+
+func openGraphOffers(doc *html.Node) []offer {
+	meta := func(properties ...string) string {
+		for _, property := range properties {
+			if node := htmlquery.FindOne(doc, fmt.Sprintf(`//meta[@property=%q]`, property)); node != nil {
+				return strings.TrimSpace(htmlquery.SelectAttr(node, "content"))
+			}
+		}
+		return ""
+	}
+
+	o := offer{
+		productName: meta("og:title"),
+		price:       meta("product:price:amount", "og:price:amount"),
+		currency:    meta("product:price:currency", "og:price:currency"),
+	}
+	if o.price == "" {
+		return nil
+	}
+
+	return []offer{o}
+}
@@ -0,0 +1,84 @@
+package parsers
+
+import "testing"
+
+func TestStructuredDataParser(t *testing.T) {
+	testParser(t, StructuredDataParser{}, "", []parserTest{
+		{
+			name: "JSON-LD offer",
+			page: `<script type="application/ld+json">
+				{"@context": "https://schema.org", "@type": "Product", "name": "Big Mac",
+				 "offers": {"@type": "Offer", "price": "5.69", "priceCurrency": "USD"}}
+			</script>`,
+			want: "5.69 USD",
+		},
+		{
+			name: "JSON-LD numeric price keeps its digits",
+			page: `<script type="application/ld+json">
+				{"@type": "Product", "name": "Big Mac", "offers": {"@type": "Offer", "price": 390.50, "priceCurrency": "JPY"}}
+			</script>`,
+			want: "390.50 JPY",
+		},
+		{
+			name: "JSON-LD graph picks the product by name",
+			page: `<script type="application/ld+json">
+				{"@graph": [
+					{"@type": "Product", "name": "McChicken", "offers": {"@type": "Offer", "price": "4.19"}},
+					{"@type": "schema:Product", "name": "Big Mac Meal", "offers": [{"@type": "http://schema.org/Offer", "price": "9.49"}]}
+				]}
+			</script>`,
+			selector: "big mac",
+			want:     "9.49",
+		},
+		{
+			name: "JSON-LD aggregate offer and price specification",
+			page: `<script type="application/ld+json">
+				{"@type": "Product", "name": "Big Mac", "offers": {"@type": "AggregateOffer", "lowPrice": "5.19",
+				 "priceSpecification": {"price": "5.29", "priceCurrency": "CAD"}}}
+			</script>`,
+			want: "5.19 CAD",
+		},
+		{
+			name: "broken JSON-LD block is skipped",
+			page: `<script type="application/ld+json">{"@type": "Product",</script>
+				<script type="application/ld+json">{"@type": "Offer", "price": "5.69"}</script>`,
+			want: "5.69",
+		},
+		{
+			name: "microdata",
+			page: `<div itemscope itemtype="https://schema.org/Product">
+				<h1 itemprop="name">Big Mac</h1>
+				<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+					<span itemprop="price" content="249.00">249 ₽</span>
+					<meta itemprop="priceCurrency" content="RUB">
+				</div>
+			</div>`,
+			selector: "Big Mac",
+			want:     "249.00 RUB",
+		},
+		{
+			name: "OpenGraph",
+			page: `<meta property="og:title" content="Big Mac">
+				<meta property="product:price:amount" content="4.79">
+				<meta property="product:price:currency" content="GBP">`,
+			want: "4.79 GBP",
+		},
+		{
+			name: "JSON-LD wins over microdata",
+			page: `<span itemprop="price" content="1.00"></span>
+				<script type="application/ld+json">{"@type": "Offer", "price": "2.00"}</script>`,
+			want: "2.00",
+		},
+		{
+			name:     "no offer for the product",
+			page:     `<script type="application/ld+json">{"@type": "Product", "name": "McFlurry", "offers": {"@type": "Offer", "price": "3.00"}}</script>`,
+			selector: "Big Mac",
+			wantErr:  ErrPriceNotFound,
+		},
+		{
+			name:    "no structured data",
+			page:    `<p>5.69</p>`,
+			wantErr: ErrPriceNotFound,
+		},
+	})
+}
@@ -1,17 +1,23 @@
 package poller
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/turbak/bigmacindex/internal/domain/currency"
 	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/money"
 	"github.com/turbak/bigmacindex/internal/domain/price"
 	"github.com/turbak/bigmacindex/internal/poller/parsers"
+	"github.com/turbak/bigmacindex/internal/poller/pricetext"
 )
 
 type LinksLister interface {
@@ -22,135 +28,321 @@ type PricesUpserter interface {
 	UpsertPrice(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, error)
 }
 
+type CurrencyGetter interface {
+	ListCurrencies(ctx context.Context) ([]currency.Currency, error)
+	GetCurrencyByCountryCode(ctx context.Context, countryCode string) (currency.Currency, error)
+}
+
 type poller struct {
 	linksLister    LinksLister
 	pricesUpserter PricesUpserter
+	currencyGetter CurrencyGetter
 	httpClient     *http.Client
+	requestTimeout time.Duration
+	hostLimiter    *hostLimiter
+	breaker        *circuitBreaker
+	retry          RetryConfig
+	workers        int
 	parsersByType  map[link.LinkType]Parser
+	archiver       ResponseArchiver
+	history        AttemptHistory
 }
 
-func NewPoller(linksLister LinksLister, pricesUpserter PricesUpserter) *poller {
+func NewPoller(linksLister LinksLister, pricesUpserter PricesUpserter, currencyGetter CurrencyGetter, cfg Config) *poller {
 	return &poller{
 		linksLister:    linksLister,
 		pricesUpserter: pricesUpserter,
+		currencyGetter: currencyGetter,
 		httpClient:     &http.Client{},
+		requestTimeout: cfg.RequestTimeout,
+		hostLimiter:    newHostLimiter(cfg.MaxConcurrentPerHost, cfg.MinIntervalPerHost),
+		breaker:        newCircuitBreaker(cfg.Breaker.FailureThreshold, cfg.Breaker.Cooldown),
+		retry:          cfg.Retry,
+		workers:        max(cfg.Workers, 1),
+		archiver:       cfg.Archiver,
+		history:        cfg.History,
 		parsersByType: map[link.LinkType]Parser{
-			link.LinkTypeHTML:  parsers.HTMLParser{},
-			link.LinkTypeJSON:  parsers.JSONParser{},
-			link.LinkTypeRegex: parsers.RegexParser{},
+			link.LinkTypeHTML:         parsers.HTMLParser{},
+			link.LinkTypeJSON:         parsers.JSONParser{},
+			link.LinkTypeRegex:        parsers.RegexParser{},
+			link.LinkTypeCSS:          parsers.CSSParser{},
+			link.LinkTypeStructured:   parsers.StructuredDataParser{},
+			link.LinkTypeEmbeddedJSON: parsers.EmbeddedJSONParser{},
 		},
 	}
 }
 
-func (p *poller) Poll(ctx context.Context) error {
+// Poll fetches every link and records its price.
+func (p *poller) Poll(ctx context.Context) (Result, error) {
 	links, err := p.linksLister.ListLinks(ctx)
 	if err != nil {
-		return err
+		return Result{}, err
 	}
 
-	for _, linkDesc := range links {
-		priceRec, err := p.fetchPriceData(ctx, linkDesc)
-		if err != nil {
-			return err
-		}
+	return p.PollLinks(ctx, links)
+}
+
+// PollLinks fetches links and records their prices, spreading them over a bounded pool of workers
+// while the host limiter keeps each retailer within its concurrency and request-rate limits.
+// A failing link doesn't stop the others; the returned error is only set when ctx is done.
+func (p *poller) PollLinks(ctx context.Context, links []link.LinkDescription) (Result, error) {
+	currencies, err := p.currencyGetter.ListCurrencies(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	detector := pricetext.NewCurrencyDetector(currencies)
+
+	if err := p.restoreBreaker(ctx); err != nil {
+		log.Printf("failed to restore circuit breakers, polling with the circuits of this process only: %v", err)
+	}
 
-		log.Printf("Fetched price for %s: %d.%02d in %s", priceRec.ProductName, priceRec.Price, priceRec.PriceCents, priceRec.CountryCode)
+	result := Result{
+		StartedAt: time.Now(),
+		Outcomes:  make([]LinkOutcome, len(links)),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(p.workers, len(links)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				result.Outcomes[idx] = p.pollLink(ctx, detector, links[idx])
+			}
+		}()
+	}
 
-		_, err = p.pricesUpserter.UpsertPrice(ctx, priceRec)
-		if err != nil {
-			return err
+	for _, idx := range interleaveByHost(links) {
+		if err := ctx.Err(); err != nil {
+			result.Outcomes[idx] = LinkOutcome{Link: links[idx], Err: newLinkError(FailureKindCanceled, err)}
+			continue
 		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	result.FinishedAt = time.Now()
+	if err := ctx.Err(); err != nil {
+		return result, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (p *poller) fetchPriceData(ctx context.Context, linkDesc link.LinkDescription) (price.PriceRecord, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, linkDesc.URL, nil)
+// Preview fetches and parses the price of linkDesc the way Poll would, without saving it.
+// Failures to poll the link are reported on the outcome; the error is only set when the poller can't run at all.
+func (p *poller) Preview(ctx context.Context, linkDesc link.LinkDescription) (LinkOutcome, error) {
+	currencies, err := p.currencyGetter.ListCurrencies(ctx)
 	if err != nil {
-		return price.PriceRecord{}, err
+		return LinkOutcome{}, fmt.Errorf("failed to list currencies: %w", err)
 	}
 
-	resp, err := p.httpClient.Do(req)
+	outcome := LinkOutcome{Link: linkDesc, StartedAt: time.Now()}
+	outcome.Price, outcome.Err = p.fetchPriceData(ctx, pricetext.NewCurrencyDetector(currencies), &outcome)
+	return outcome, nil
+}
+
+func (p *poller) pollLink(ctx context.Context, detector *pricetext.CurrencyDetector, linkDesc link.LinkDescription) LinkOutcome {
+	outcome := LinkOutcome{Link: linkDesc, StartedAt: time.Now()}
+
+	priceRec, linkErr := p.fetchPriceData(ctx, detector, &outcome)
+	if linkErr != nil {
+		outcome.Err = linkErr
+		return outcome
+	}
+
+	log.Printf("Fetched price for %s: %s in %s", priceRec.ProductName, priceRec.Price, priceRec.CountryCode)
+
+	priceRec, err := p.pricesUpserter.UpsertPrice(ctx, priceRec)
 	if err != nil {
-		return price.PriceRecord{}, err
+		outcome.Err = newLinkError(FailureKindStorage, err)
+		return outcome
 	}
-	defer resp.Body.Close()
 
-	parser, ok := p.parsersByType[linkDesc.LinkType]
-	if !ok {
-		return price.PriceRecord{}, fmt.Errorf("no parser for link type %s", linkDesc.LinkType)
+	outcome.Price = priceRec
+	return outcome
+}
+
+// fetchPriceData fetches and parses the price of outcome.Link, recording the HTTP status and raw price string on outcome.
+// The currency is taken from the page when it states one, and must then match the currency of the link's country.
+func (p *poller) fetchPriceData(ctx context.Context, detector *pricetext.CurrencyDetector, outcome *LinkOutcome) (price.PriceRecord, *LinkError) {
+	linkDesc := outcome.Link
+
+	vars, linkErr := p.runSteps(ctx, outcome)
+	if linkErr != nil {
+		return price.PriceRecord{}, linkErr
 	}
 
-	priceValueStr, err := parser.ParsePriceStringFromReader(resp.Body, linkDesc.PriceSelector)
-	if err != nil {
-		return price.PriceRecord{}, err
+	spec := requestSpec{URL: linkDesc.URL, Config: linkDesc.Request}
+	extractions := linkDesc.Extractions()
+	archived := len(outcome.ArchiveKeys)
+	priceValueStr, matched, linkErr := p.fetchValue(ctx, outcome, spec, vars, extractions)
+	if len(outcome.ArchiveKeys) > archived {
+		outcome.ResponseKey = outcome.ArchiveKeys[len(outcome.ArchiveKeys)-1]
+	}
+	if linkErr != nil {
+		return price.PriceRecord{}, linkErr
 	}
 
-	priceValueStr = sanitizePriceString(priceValueStr)
-	priceComp := parsePriceString(priceValueStr)
+	return p.priceRecord(ctx, detector, outcome, priceValueStr, matched)
+}
 
-	var priceInt, priceCents int
-	if priceComp.IntegerPart != "" {
-		priceInt, err = strconv.Atoi(priceComp.IntegerPart)
-		if err != nil {
-			return price.PriceRecord{}, fmt.Errorf("invalid integer part %s: %w", priceComp.IntegerPart, err)
-		}
+// priceRecord turns the value extracted for outcome.Link by its extraction at index matched into
+// a price dated by when the link was polled, recording the raw value on outcome.
+func (p *poller) priceRecord(ctx context.Context, detector *pricetext.CurrencyDetector, outcome *LinkOutcome, priceValueStr string, matched int) (price.PriceRecord, *LinkError) {
+	linkDesc := outcome.Link
+
+	outcome.RawPrice = priceValueStr
+	outcome.MatchedRule = matched
+	outcome.MatchedSelector = linkDesc.Extractions()[matched].String()
+	if matched > 0 {
+		log.Printf("Link #%d (%s, %s) fell back to %s", linkDesc.ID, linkDesc.ProductName, linkDesc.CountryCode, outcome.MatchedSelector)
 	}
 
-	if priceComp.DecimalPart != "" {
-		priceCents, err = strconv.Atoi(priceComp.DecimalPart)
-		if err != nil {
-			return price.PriceRecord{}, fmt.Errorf("invalid decimal part %s: %w", priceComp.DecimalPart, err)
-		}
+	cur, err := p.currencyGetter.GetCurrencyByCountryCode(ctx, linkDesc.CountryCode)
+	if err != nil {
+		return price.PriceRecord{}, newLinkError(FailureKindConfig, fmt.Errorf("failed to resolve currency for country %s: %w", linkDesc.CountryCode, err))
+	}
+
+	if detected, match, ok := detector.Detect(priceValueStr, cur.Code); ok && detected != cur.Code {
+		return price.PriceRecord{}, newLinkError(FailureKindCurrency,
+			fmt.Errorf("page states %s (%q) but %s uses %s", detected, match, linkDesc.CountryCode, cur.Code))
+	}
+
+	amount, err := pricetext.Parse(priceValueStr, pricetext.Options{
+		MinorUnits: cur.MinorUnits,
+		Locale:     pricetext.LocaleForCountry(linkDesc.CountryCode),
+	})
+	if err != nil {
+		return price.PriceRecord{}, newLinkError(FailureKindParse, err)
 	}
 
 	return price.PriceRecord{
 		ProductName: linkDesc.ProductName,
-		Price:       int32(priceInt),
-		PriceCents:  int32(priceCents),
-		Currency:    priceComp.Currency,
+		Price:       money.New(amount, cur.Code, cur.MinorUnits),
 		CountryCode: linkDesc.CountryCode,
-		CreatedDate: time.Now().Format(time.DateOnly),
+		CreatedDate: outcome.StartedAt.Format(time.DateOnly),
+		Source:      price.SourcePoll,
 	}, nil
 }
 
-func sanitizePriceString(priceStr string) string {
-	var result strings.Builder
-	for _, r := range priceStr {
-		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
-			result.WriteRune(r)
+// fetchValue makes the request of spec and reads a value from the response with the first of
+// extractions that finds one, returning its index.
+func (p *poller) fetchValue(ctx context.Context, outcome *LinkOutcome, spec requestSpec, vars map[string]string, extractions []link.Extraction) (string, int, *LinkError) {
+	body, linkErr := p.fetchBody(ctx, outcome, spec, vars)
+	if linkErr != nil {
+		return "", 0, linkErr
+	}
+
+	return p.extractValue(body, extractions)
+}
+
+// extractValue reads a value from body with the first of extractions that finds one, returning its index.
+func (p *poller) extractValue(body []byte, extractions []link.Extraction) (string, int, *LinkError) {
+	linkErrs := make([]*LinkError, 0, len(extractions))
+	for i, extraction := range extractions {
+		value, linkErr := p.extract(body, extraction)
+		if linkErr == nil {
+			return value, i, nil
 		}
+		linkErrs = append(linkErrs, linkErr)
+	}
+
+	return "", 0, joinExtractionErrors(extractions, linkErrs)
+}
+
+// fetchBody makes the request of spec and returns the response body decoded to UTF-8.
+func (p *poller) fetchBody(ctx context.Context, outcome *LinkOutcome, spec requestSpec, vars map[string]string) ([]byte, *LinkError) {
+	req, err := prepareRequest(spec, requestData{LinkDescription: outcome.Link, Vars: vars})
+	if err != nil {
+		return nil, newLinkError(FailureKindConfig, err)
+	}
+
+	resp, linkErr := p.fetch(ctx, outcome, req)
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, newLinkError(FailureKindParse, err)
+	}
+
+	return body, nil
+}
+
+func (p *poller) extract(body []byte, extraction link.Extraction) (string, *LinkError) {
+	parser, ok := p.parsersByType[extraction.LinkType]
+	if !ok {
+		return "", newLinkError(FailureKindConfig, fmt.Errorf("no parser for link type %s", extraction.LinkType))
 	}
 
-	return result.String()
+	value, err := parser.ParsePriceStringFromReader(bytes.NewReader(body), extraction.Selector)
+	if errors.Is(err, parsers.ErrPriceNotFound) {
+		return "", newLinkError(FailureKindSelectorNotFound, err)
+	}
+	if err != nil {
+		return "", newLinkError(FailureKindParse, err)
+	}
+
+	return value, nil
 }
 
-func parsePriceString(priceStr string) priceComponent {
-	var integerPart, decimalPart string
+// joinExtractionErrors combines the failures of every extraction. The kind is selector_not_found
+// only when all of them merely found nothing.
+func joinExtractionErrors(extractions []link.Extraction, linkErrs []*LinkError) *LinkError {
+	if len(linkErrs) == 1 {
+		return linkErrs[0]
+	}
 
-	var priceParts []string
-	if strings.Contains(priceStr, ".") {
-		priceParts = strings.SplitN(priceStr, ".", 2)
-	} else if strings.Contains(priceStr, ",") {
-		priceParts = strings.SplitN(priceStr, ",", 2)
-	} else {
-		priceParts = []string{priceStr}
+	kind := FailureKindSelectorNotFound
+	errs := make([]error, len(linkErrs))
+	for i, linkErr := range linkErrs {
+		if linkErr.Kind != FailureKindSelectorNotFound {
+			kind = linkErr.Kind
+		}
+		if i == 0 {
+			errs[i] = linkErr.Err
+			continue
+		}
+		errs[i] = fmt.Errorf("fallback %d (%s): %w", i, extractions[i], linkErr.Err)
 	}
 
-	integerPart = priceParts[0]
-	if len(priceParts) == 2 {
-		decimalPart = priceParts[1]
+	return newLinkError(kind, errors.Join(errs...))
+}
+
+// interleaveByHost orders link indexes round-robin across hosts, so a worker waiting on a
+// rate-limited host is unlikely to hold up links on other hosts.
+func interleaveByHost(links []link.LinkDescription) []int {
+	var hosts []string
+	idxsByHost := make(map[string][]int)
+	for idx, linkDesc := range links {
+		host := linkHost(linkDesc)
+		if _, ok := idxsByHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		idxsByHost[host] = append(idxsByHost[host], idx)
 	}
 
-	return priceComponent{
-		IntegerPart: integerPart,
-		DecimalPart: decimalPart,
+	order := make([]int, 0, len(links))
+	for len(order) < len(links) {
+		for _, host := range hosts {
+			if idxs := idxsByHost[host]; len(idxs) > 0 {
+				order = append(order, idxs[0])
+				idxsByHost[host] = idxs[1:]
+			}
+		}
 	}
+
+	return order
 }
 
-type priceComponent struct {
-	IntegerPart string
-	DecimalPart string
-	Currency    string
+func linkHost(linkDesc link.LinkDescription) string {
+	u, err := url.Parse(linkDesc.URL)
+	if err != nil {
+		return linkDesc.URL
+	}
+	return strings.ToLower(u.Hostname())
 }
@@ -0,0 +1,138 @@
+package pricetext
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/turbak/bigmacindex/internal/domain/currency"
+)
+
+var isoCodeRe = regexp.MustCompile(`\b[A-Z]{3}\b`)
+
+// CurrencyDetector finds the currency a price string is written in, from ISO 4217 codes
+// ("USD") or symbols ("$", "₽", "руб.", "kr") as listed in the currencies table.
+type CurrencyDetector struct {
+	codes map[string]bool
+	// symbols are ordered longest first so "R$" and "US$" win over "$".
+	symbols []currencySymbol
+}
+
+type currencySymbol struct {
+	symbol     string
+	currencies []string
+	// alphabetic symbols such as "kr" or "руб." only match as whole words, and single letters
+	// such as "R" or "L" only right next to a number.
+	alphabetic bool
+	letter     bool
+}
+
+func NewCurrencyDetector(currencies []currency.Currency) *CurrencyDetector {
+	detector := &CurrencyDetector{codes: make(map[string]bool, len(currencies))}
+
+	bySymbol := make(map[string][]string)
+	for _, cur := range currencies {
+		detector.codes[cur.Code] = true
+		for _, symbol := range cur.Symbols {
+			bySymbol[symbol] = append(bySymbol[symbol], cur.Code)
+		}
+	}
+
+	for symbol, codes := range bySymbol {
+		slices.Sort(codes)
+		detector.symbols = append(detector.symbols, currencySymbol{
+			symbol:     symbol,
+			currencies: codes,
+			alphabetic: strings.IndexFunc(symbol, unicode.IsLetter) >= 0,
+			letter:     utf8.RuneCountInString(symbol) == 1 && strings.IndexFunc(symbol, unicode.IsLetter) == 0,
+		})
+	}
+	slices.SortFunc(detector.symbols, func(a, b currencySymbol) int {
+		if n := utf8.RuneCountInString(b.symbol) - utf8.RuneCountInString(a.symbol); n != 0 {
+			return n
+		}
+		return strings.Compare(a.symbol, b.symbol)
+	})
+
+	return detector
+}
+
+// Detect returns the currency stated in s and the text it was recognized from. ISO codes take
+// precedence over symbols. A symbol shared by several currencies ("$", "kr") resolves to
+// expected when it is one of them, otherwise to the alphabetically first candidate so the
+// caller can report the mismatch.
+func (d *CurrencyDetector) Detect(s, expected string) (string, string, bool) {
+	for _, code := range isoCodeRe.FindAllString(s, -1) {
+		if d.codes[code] {
+			return code, code, true
+		}
+	}
+
+	claimed := make([]bool, len(s))
+	bestPos, bestCode, bestMatch := -1, "", ""
+	for _, sym := range d.symbols {
+		for offset := 0; offset < len(s); {
+			idx := strings.Index(s[offset:], sym.symbol)
+			if idx < 0 {
+				break
+			}
+			start, end := offset+idx, offset+idx+len(sym.symbol)
+			offset = end
+
+			if slices.Contains(claimed[start:end], true) {
+				continue
+			}
+			if sym.alphabetic && !isWordBoundary(s, start, end) {
+				continue
+			}
+			if sym.letter && !isNextToDigit(s, start, end) {
+				continue
+			}
+			for i := start; i < end; i++ {
+				claimed[i] = true
+			}
+
+			if bestPos < 0 || start < bestPos {
+				bestPos, bestCode, bestMatch = start, sym.resolve(expected), sym.symbol
+			}
+		}
+	}
+
+	return bestCode, bestMatch, bestPos >= 0
+}
+
+func (s currencySymbol) resolve(expected string) string {
+	if slices.Contains(s.currencies, expected) {
+		return expected
+	}
+	return s.currencies[0]
+}
+
+func isWordBoundary(s string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(s[:start])
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		r, _ := utf8.DecodeRuneInString(s[end:])
+		if unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNextToDigit(s string, start, end int) bool {
+	before := strings.TrimRightFunc(s[:start], unicode.IsSpace)
+	if r, _ := utf8.DecodeLastRuneInString(before); unicode.IsDigit(r) {
+		return true
+	}
+
+	after := strings.TrimLeftFunc(s[end:], unicode.IsSpace)
+	r, _ := utf8.DecodeRuneInString(after)
+	return unicode.IsDigit(r)
+}
@@ -0,0 +1,59 @@
+package pricetext
+
+import (
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/currency"
+)
+
+func TestCurrencyDetectorDetect(t *testing.T) {
+	detector := NewCurrencyDetector([]currency.Currency{
+		{Code: "USD", Symbols: []string{"$", "US$"}},
+		{Code: "CAD", Symbols: []string{"$", "C$"}},
+		{Code: "BRL", Symbols: []string{"R$"}},
+		{Code: "ZAR", Symbols: []string{"R"}},
+		{Code: "EUR", Symbols: []string{"€"}},
+		{Code: "RUB", Symbols: []string{"₽", "руб."}},
+		{Code: "SEK", Symbols: []string{"kr"}},
+		{Code: "NOK", Symbols: []string{"kr"}},
+		{Code: "JPY", Symbols: []string{"¥", "円"}},
+		{Code: "GBP", Symbols: []string{"£"}},
+	})
+
+	tests := []struct {
+		name      string
+		in        string
+		expected  string
+		wantCode  string
+		wantMatch string
+		wantOK    bool
+	}{
+		{"ISO code", "5.69 USD", "USD", "USD", "USD", true},
+		{"ISO code wins over symbol", "€ 4,50 EUR", "EUR", "EUR", "EUR", true},
+		{"ISO code of another currency", "4.50 GBP", "EUR", "GBP", "GBP", true},
+		{"unknown ISO code falls back to symbol", "ABC €4,50", "EUR", "EUR", "€", true},
+		{"symbol", "€4,50", "EUR", "EUR", "€", true},
+		{"longest symbol wins", "R$ 22,90", "BRL", "BRL", "R$", true},
+		{"prefixed dollar", "US$5.69", "USD", "USD", "US$", true},
+		{"shared symbol resolves to expected", "$7.49", "CAD", "CAD", "$", true},
+		{"shared symbol of another currency", "$7.49", "EUR", "CAD", "$", true},
+		{"shared word symbol", "59 kr", "NOK", "NOK", "kr", true},
+		{"word symbol with dot", "199 руб.", "RUB", "RUB", "руб.", true},
+		{"word symbol inside a word", "krone 59", "SEK", "", "", false},
+		{"letter next to a number", "R39.90", "ZAR", "ZAR", "R", true},
+		{"letter away from a number", "Regular 39.90", "ZAR", "", "", false},
+		{"first symbol wins", "¥390 (£2.10)", "JPY", "JPY", "¥", true},
+		{"trailing symbol", "390円", "JPY", "JPY", "円", true},
+		{"no currency", "5.69", "USD", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, match, ok := detector.Detect(tt.in, tt.expected)
+			if code != tt.wantCode || match != tt.wantMatch || ok != tt.wantOK {
+				t.Errorf("Detect(%q, %q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.in, tt.expected, code, match, ok, tt.wantCode, tt.wantMatch, tt.wantOK)
+			}
+		})
+	}
+}
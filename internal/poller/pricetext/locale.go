@@ -0,0 +1,28 @@
+package pricetext
+
+// Locale describes how a country conventionally writes numbers. It is only consulted when a
+// price string can't be read unambiguously on its own, e.g. "1.250" for a 3-digit currency.
+type Locale struct {
+	DecimalSeparator rune
+	GroupSeparator   rune
+}
+
+var (
+	dotDecimal   = Locale{DecimalSeparator: '.', GroupSeparator: ','}
+	commaDecimal = Locale{DecimalSeparator: ',', GroupSeparator: '.'}
+)
+
+var commaDecimalCountries = map[string]bool{
+	"AR": true, "AT": true, "AZ": true, "BE": true, "BR": true, "CL": true, "CO": true, "CR": true,
+	"CZ": true, "DE": true, "DK": true, "ES": true, "FI": true, "FR": true, "GR": true, "HU": true,
+	"ID": true, "IT": true, "KZ": true, "MD": true, "NL": true, "NO": true, "PL": true, "PT": true,
+	"RO": true, "RU": true, "SE": true, "TR": true, "UA": true, "UY": true, "VN": true, "ZA": true,
+}
+
+// LocaleForCountry returns the number format of an ISO 3166 alpha-2 country, defaulting to a dot decimal separator.
+func LocaleForCountry(countryCode string) Locale {
+	if commaDecimalCountries[countryCode] {
+		return commaDecimal
+	}
+	return dotDecimal
+}
@@ -0,0 +1,231 @@
+package pricetext
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrNoNumber = errors.New("no number found")
+
+// maxDigits keeps amounts within int64 once scaled to minor units.
+const maxDigits = 18
+
+type Options struct {
+	// MinorUnits is the number of decimal digits of the currency, e.g. 2 for USD, 0 for JPY, 3 for KWD.
+	MinorUnits int
+	Locale     Locale
+}
+
+// separator classes of a normalized price string
+const (
+	sepAmbiguous = iota // '.' or ',' — either decimal or grouping depending on context
+	sepGroup            // spaces and apostrophes, only ever grouping
+	sepDecimal          // the Arabic decimal separator, only ever decimal
+)
+
+type token struct {
+	digits     []string // digit runs between separators
+	separators []rune   // separators[i] sits between digits[i] and digits[i+1]
+	classes    []int
+}
+
+// Parse reads the first number in s and returns it in the currency's minor units, so
+// "1.299,00 €", "1 299,50", "12,345", "¥390" and "٣٩٠" all parse as they are meant.
+// Fractions shorter than the currency's minor units are padded ("5.5" is 550 cents) and
+// longer ones are rounded half up.
+func Parse(s string, opts Options) (int64, error) {
+	tok, ok := firstNumber(s)
+	if !ok {
+		return 0, fmt.Errorf("%w in %q", ErrNoNumber, s)
+	}
+
+	integerPart, fractionPart := tok.split(opts)
+
+	amount, err := scale(integerPart, fractionPart, opts.MinorUnits)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price %q: %w", s, err)
+	}
+	return amount, nil
+}
+
+// firstNumber finds the first run of digits and the separators between them.
+func firstNumber(s string) (token, bool) {
+	runes := []rune(s)
+
+	var (
+		tok     token
+		current strings.Builder
+		started bool
+	)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if d, ok := digitValue(r); ok {
+			current.WriteByte(byte('0' + d))
+			started = true
+			continue
+		}
+		if !started {
+			continue
+		}
+
+		sep, class, isSep := classify(r)
+		if !isSep || i+1 >= len(runes) {
+			break
+		}
+		if _, nextIsDigit := digitValue(runes[i+1]); !nextIsDigit {
+			break
+		}
+		// A space only groups thousands: "1 299" is one number, "2 x 199" or "2 199 99" is not.
+		if class == sepGroup && !followedByGroup(runes[i+1:]) {
+			break
+		}
+
+		tok.digits = append(tok.digits, current.String())
+		tok.separators = append(tok.separators, sep)
+		tok.classes = append(tok.classes, class)
+		current.Reset()
+	}
+
+	if !started {
+		return token{}, false
+	}
+	tok.digits = append(tok.digits, current.String())
+	return tok, true
+}
+
+// split decides which separator, if any, is the decimal one and joins the digits around it.
+func (t token) split(opts Options) (string, string) {
+	decimalIdx := t.decimalIndex(opts)
+	if decimalIdx < 0 {
+		return strings.Join(t.digits, ""), ""
+	}
+
+	return strings.Join(t.digits[:decimalIdx+1], ""), strings.Join(t.digits[decimalIdx+1:], "")
+}
+
+func (t token) decimalIndex(opts Options) int {
+	last := -1
+	counts := make(map[rune]int)
+	for i, class := range t.classes {
+		switch class {
+		case sepDecimal:
+			return i
+		case sepAmbiguous:
+			counts[t.separators[i]]++
+			last = i
+		}
+	}
+	if last < 0 {
+		return -1
+	}
+
+	sep := t.separators[last]
+	// Both '.' and ',' appear: the last one is the decimal separator, as in "1.299,00" or "1,299.00".
+	if len(counts) > 1 {
+		if counts[sep] > 1 {
+			return -1
+		}
+		return last
+	}
+
+	// The only separator kind repeats, so it groups thousands: "1.234.567".
+	if counts[sep] > 1 {
+		return -1
+	}
+
+	fractionLen := len(t.digits[last+1])
+	integerPart := strings.Join(t.digits[:last+1], "")
+	switch {
+	case fractionLen != 3:
+		return last
+	case integerPart == "0":
+		return last
+	case opts.MinorUnits == 3:
+		// "1.250" for a dinar is genuinely ambiguous, so follow the country's convention.
+		if sep == opts.Locale.GroupSeparator {
+			return -1
+		}
+		return last
+	default:
+		// Three digits can't be a fraction of a currency with fewer minor units: "12,345" is 12345.
+		return -1
+	}
+}
+
+func scale(integerPart, fractionPart string, minorUnits int) (int64, error) {
+	integerPart = strings.TrimLeft(integerPart, "0")
+	if len(integerPart)+minorUnits > maxDigits {
+		return 0, errors.New("amount is too large")
+	}
+
+	roundUp := false
+	if len(fractionPart) > minorUnits {
+		roundUp = fractionPart[minorUnits] >= '5'
+		fractionPart = fractionPart[:minorUnits]
+	}
+	fractionPart += strings.Repeat("0", minorUnits-len(fractionPart))
+
+	var amount int64
+	for _, d := range integerPart + fractionPart {
+		amount = amount*10 + int64(d-'0')
+	}
+	if roundUp {
+		amount++
+	}
+
+	return amount, nil
+}
+
+// classify reports whether r separates digit groups, normalizing the many ways it can be written.
+func classify(r rune) (rune, int, bool) {
+	switch r {
+	case '.', '\uff0e':
+		return '.', sepAmbiguous, true
+	case ',', '\uff0c':
+		return ',', sepAmbiguous, true
+	case '\u066b':
+		return '.', sepDecimal, true
+	case '\u066c', '\'', '\u2019', '\u02bc':
+		return '\'', sepGroup, true
+	case ' ', '\u00a0', '\u202f', '\u2009', '\u2007':
+		return ' ', sepGroup, true
+	}
+	return 0, 0, false
+}
+
+// followedByGroup reports whether runes start with exactly three digits.
+func followedByGroup(runes []rune) bool {
+	for i := 0; i < 3; i++ {
+		if i >= len(runes) {
+			return false
+		}
+		if _, ok := digitValue(runes[i]); !ok {
+			return false
+		}
+	}
+	if len(runes) > 3 {
+		_, ok := digitValue(runes[3])
+		return !ok
+	}
+	return true
+}
+
+// digitValue returns the value of any Unicode decimal digit, such as '٣' or '３'.
+// Decimal digits are encoded in contiguous runs starting at zero, so the value is the
+// offset from the start of the run.
+func digitValue(r rune) (int, bool) {
+	if r >= '0' && r <= '9' {
+		return int(r - '0'), true
+	}
+	if !unicode.IsDigit(r) {
+		return 0, false
+	}
+
+	zero := r
+	for unicode.IsDigit(zero - 1) {
+		zero--
+	}
+	return int(r-zero) % 10, true
+}
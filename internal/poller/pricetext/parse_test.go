@@ -0,0 +1,93 @@
+package pricetext
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var (
+		usd = Options{MinorUnits: 2, Locale: LocaleForCountry("US")}
+		eur = Options{MinorUnits: 2, Locale: LocaleForCountry("DE")}
+		rub = Options{MinorUnits: 2, Locale: LocaleForCountry("RU")}
+		jpy = Options{MinorUnits: 0, Locale: LocaleForCountry("JP")}
+		kwd = Options{MinorUnits: 3, Locale: LocaleForCountry("KW")}
+	)
+
+	tests := []struct {
+		name string
+		in   string
+		opts Options
+		want int64
+	}{
+		{"plain dollars", "5.69", usd, 569},
+		{"dollar sign", "$5.69", usd, 569},
+		{"US grouping", "$1,299.00", usd, 129900},
+		{"US grouping without decimals", "12,345", usd, 1234500},
+		{"European grouping", "1.299,00 €", eur, 129900},
+		{"European decimal comma", "5,5", eur, 550},
+		{"dot decimal", "5.5", usd, 550},
+		{"single fraction digit pads", "5.5", eur, 550},
+		{"comma decimal in US page", "5,5", usd, 550},
+		{"repeated dots group thousands", "1.234.567", eur, 123456700},
+		{"space grouping", "1 299,50", rub, 129950},
+		{"NBSP grouping", "1 299,50 ₽", rub, 129950},
+		{"narrow NBSP grouping", "1 299,50", rub, 129950},
+		{"apostrophe grouping", "CHF 1'299.50", usd, 129950},
+		{"rouble abbreviation", "199 руб.", rub, 19900},
+		{"yen", "¥390", jpy, 390},
+		{"yen with grouping", "¥1,390", jpy, 1390},
+		{"yen fraction is rounded", "390.6円", jpy, 391},
+		{"leading zero fraction", "0,990", eur, 99},
+		{"Arabic-Indic digits", "٣٩٠", jpy, 390},
+		{"Arabic-Indic decimal separator", "١٫٢٥٠ د.ك", kwd, 1250},
+		{"Arabic-Indic grouping", "١٬٢٣٤٫٥٠٠", kwd, 1234500},
+		{"fullwidth digits", "３９０円", jpy, 390},
+		{"dinar with three decimals", "KWD 1.250", kwd, 1250},
+		{"dinar in a comma-decimal locale", "1,250 DT", Options{MinorUnits: 3, Locale: commaDecimal}, 1250},
+		{"dinar grouped by locale", "1.250", Options{MinorUnits: 3, Locale: commaDecimal}, 1250000},
+		{"dinar padded", "KD 1.2", kwd, 1200},
+		{"rounds half up", "4.9950", usd, 500},
+		{"three digits after a lone dot are thousands", "4.995", usd, 499500},
+		{"first number only", "2 for $5.00", usd, 200},
+		{"spaced numbers stay apart", "2 x 199", rub, 200},
+		{"space only groups thousands", "2 199 99", rub, 219900},
+		{"text around", "Price: 4,79 €*", eur, 479},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in, tt.opts)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+	}{
+		{"empty", "", ErrNoNumber},
+		{"no digits", "sold out", ErrNoNumber},
+		{"too large", "12345678901234567890", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.in, Options{MinorUnits: 2, Locale: dotDecimal})
+			if err == nil {
+				t.Fatalf("Parse(%q) returned no error", tt.in)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/archive"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+	"github.com/turbak/bigmacindex/internal/poller/pricetext"
+)
+
+// Snapshot is an archived response of a link, to be parsed again.
+type Snapshot struct {
+	Link     link.LinkDescription
+	Response archive.Record
+	// PolledAt is when the response was fetched by a poll; replayed prices are dated by it.
+	PolledAt time.Time
+}
+
+// Replay extracts the prices of snapshots with their links' current selectors and parsers, as Poll
+// would have with the archived responses. It makes no requests and saves nothing. Failures to parse a
+// snapshot are reported on its outcome; the error is only set when the poller can't run at all.
+func (p *poller) Replay(ctx context.Context, snapshots []Snapshot) ([]LinkOutcome, error) {
+	currencies, err := p.currencyGetter.ListCurrencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list currencies: %w", err)
+	}
+	detector := pricetext.NewCurrencyDetector(currencies)
+
+	outcomes := make([]LinkOutcome, len(snapshots))
+	for i, snapshot := range snapshots {
+		outcome := LinkOutcome{
+			Link:       snapshot.Link,
+			StartedAt:  snapshot.PolledAt,
+			HTTPStatus: snapshot.Response.StatusCode,
+		}
+		outcome.Price, outcome.Err = p.replaySnapshot(ctx, detector, &outcome, snapshot.Response)
+		outcomes[i] = outcome
+	}
+
+	return outcomes, nil
+}
+
+func (p *poller) replaySnapshot(ctx context.Context, detector *pricetext.CurrencyDetector, outcome *LinkOutcome, resp archive.Record) (price.PriceRecord, *LinkError) {
+	if resp.StatusCode >= http.StatusBadRequest {
+		return price.PriceRecord{}, newLinkError(FailureKindHTTP, fmt.Errorf("archived response has status %d", resp.StatusCode))
+	}
+
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return price.PriceRecord{}, newLinkError(FailureKindParse, err)
+	}
+
+	priceValueStr, matched, linkErr := p.extractValue(body, outcome.Link.Extractions())
+	if linkErr != nil {
+		return price.PriceRecord{}, linkErr
+	}
+
+	return p.priceRecord(ctx, detector, outcome, priceValueStr, matched)
+}
@@ -0,0 +1,317 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+// envPrefix is the prefix of the environment variables request templates may read. Anyone who can edit
+// a link can send its requests anywhere, so the other variables, e.g. credentials of the poller's own
+// host, are kept out of reach.
+const envPrefix = "BIGMAC_"
+
+var requestFuncs = template.FuncMap{"env": requestEnv}
+
+// redactedFuncs render a request's URL without the values of environment variables, for storing and showing it.
+var redactedFuncs = template.FuncMap{"env": func(string) string { return "REDACTED" }}
+
+func requestEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, envPrefix) {
+		return "", fmt.Errorf("environment variable %q is not available to requests, only %s* ones are", name, envPrefix)
+	}
+	return os.Getenv(name), nil
+}
+
+// requestSpec is one HTTP request made for a link: one of its steps or the final price request.
+type requestSpec struct {
+	URL    string
+	Config link.RequestConfig
+}
+
+// requestData is what request templates are executed with. Vars holds the values extracted by the
+// link's earlier steps, e.g. {{ .Vars.token }}.
+type requestData struct {
+	link.LinkDescription
+	Vars map[string]string
+}
+
+// preparedRequest is a requestSpec with its templates rendered, so retries send the same request.
+type preparedRequest struct {
+	method string
+	url    *url.URL
+	// publicURL is url without its credentials and the values of environment variables,
+	// the form that is logged, archived and recorded in errors.
+	publicURL string
+	header    http.Header
+	host      string
+	body      string
+	timeout   time.Duration
+}
+
+func prepareRequest(spec requestSpec, data requestData) (preparedRequest, error) {
+	cfg := spec.Config
+
+	u, err := renderURL(spec, data, requestFuncs)
+	if err != nil {
+		return preparedRequest{}, err
+	}
+	public, err := renderURL(spec, data, redactedFuncs)
+	if err != nil {
+		return preparedRequest{}, err
+	}
+
+	prepared := preparedRequest{
+		method:    http.MethodGet,
+		url:       u,
+		publicURL: public.Redacted(),
+		header:    make(http.Header),
+		timeout:   cfg.Timeout,
+	}
+	if cfg.Method != "" {
+		prepared.method = cfg.Method
+	}
+
+	prepared.body, err = renderRequestTemplate("body", cfg.Body, data, requestFuncs)
+	if err != nil {
+		return preparedRequest{}, err
+	}
+
+	for _, header := range cfg.Headers {
+		value, err := renderRequestTemplate("header "+header.Name, header.Value, data, requestFuncs)
+		if err != nil {
+			return preparedRequest{}, err
+		}
+		if strings.EqualFold(header.Name, "Host") {
+			prepared.host = value
+			continue
+		}
+		prepared.header.Add(header.Name, value)
+	}
+
+	return prepared, nil
+}
+
+// renderURL renders the URL of spec with its query parameters. A URL that fails to parse is left
+// out of the error, it may hold secrets.
+func renderURL(spec requestSpec, data requestData, funcs template.FuncMap) (*url.URL, error) {
+	rawURL, err := renderRequestTemplate("URL", spec.URL, data, funcs)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, redactURLError(err, spec.URL)
+	}
+
+	if len(spec.Config.Query) > 0 {
+		query := u.Query()
+		for _, param := range spec.Config.Query {
+			query.Del(param.Name)
+		}
+		for _, param := range spec.Config.Query {
+			value, err := renderRequestTemplate("query "+param.Name, param.Value, data, funcs)
+			if err != nil {
+				return nil, err
+			}
+			query.Add(param.Name, value)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u, nil
+}
+
+func (r preparedRequest) newHTTPRequest(ctx context.Context) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url.String(), strings.NewReader(r.body))
+	if err != nil {
+		return nil, redactURLError(err, r.publicURL)
+	}
+	if r.body == "" {
+		req.Body, req.ContentLength = http.NoBody, 0
+	}
+
+	req.Header = r.header.Clone()
+	if r.host != "" {
+		req.Host = r.host
+	}
+	return req, nil
+}
+
+func (r preparedRequest) hostname() string {
+	return hostname(r.url)
+}
+
+// hostname returns the host of u the way the circuit breaker and the host limiter key it.
+func hostname(u *url.URL) string {
+	return strings.ToLower(u.Hostname())
+}
+
+// redactURLError replaces the URL in err, which net/http and net/url quote in full, with public.
+func redactURLError(err error, public string) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = public
+	}
+	return err
+}
+
+// ValidateRequests reports the errors the poller would hit building any of linkDesc's requests,
+// such as invalid templates or references to variables no earlier step extracts.
+func ValidateRequests(linkDesc link.LinkDescription) error {
+	vars := make(map[string]string)
+	for _, step := range linkDesc.Steps {
+		if err := validateRequest(requestSpec{URL: step.URL, Config: step.Request}, vars); err != nil {
+			return fmt.Errorf("step %s: %w", step.Name, err)
+		}
+		vars[step.Name] = ""
+	}
+
+	return validateRequest(requestSpec{URL: linkDesc.URL, Config: linkDesc.Request}, vars)
+}
+
+func validateRequest(spec requestSpec, vars map[string]string) error {
+	cfg := spec.Config
+	if cfg.Method != "" && !isToken(cfg.Method) {
+		return fmt.Errorf("invalid HTTP method %q", cfg.Method)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	for _, header := range cfg.Headers {
+		if !isToken(header.Name) {
+			return fmt.Errorf("invalid header name %q", header.Name)
+		}
+	}
+
+	if _, err := prepareRequest(spec, requestData{Vars: vars}); err != nil {
+		return err
+	}
+
+	// Rendering skips the branches of conditionals, so check every env call for a variable name it may read.
+	texts := []string{spec.URL, cfg.Body}
+	for _, params := range [][]link.Param{cfg.Query, cfg.Headers} {
+		for _, param := range params {
+			texts = append(texts, param.Value)
+		}
+	}
+	for _, text := range texts {
+		if err := validateEnvCalls(text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateEnvCalls reports an env call of a template that doesn't name an allowed variable literally.
+func validateEnvCalls(text string) error {
+	if !strings.Contains(text, "{{") {
+		return nil
+	}
+
+	tmpl, err := template.New("").Funcs(requestFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var check func(node parse.Node) error
+	check = func(node parse.Node) error {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return nil
+			}
+			for _, child := range n.Nodes {
+				if err := check(child); err != nil {
+					return err
+				}
+			}
+		case *parse.ActionNode:
+			return check(n.Pipe)
+		case *parse.IfNode:
+			return checkBranch(check, &n.BranchNode)
+		case *parse.RangeNode:
+			return checkBranch(check, &n.BranchNode)
+		case *parse.WithNode:
+			return checkBranch(check, &n.BranchNode)
+		case *parse.TemplateNode:
+			return check(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return nil
+			}
+			for _, cmd := range n.Cmds {
+				if err := check(cmd); err != nil {
+					return err
+				}
+			}
+		case *parse.CommandNode:
+			if ident, ok := n.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "env" {
+				name, ok := n.Args[len(n.Args)-1].(*parse.StringNode)
+				if len(n.Args) != 2 || !ok {
+					return fmt.Errorf("env must be given a quoted variable name, as in {{ env %q }}", envPrefix+"API_KEY")
+				}
+				if _, err := requestEnv(name.Text); err != nil {
+					return err
+				}
+			}
+			for _, arg := range n.Args {
+				if err := check(arg); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	return check(tmpl.Tree.Root)
+}
+
+func checkBranch(check func(parse.Node) error, branch *parse.BranchNode) error {
+	for _, node := range []parse.Node{branch.Pipe, branch.List, branch.ElseList} {
+		if err := check(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderRequestTemplate(name, text string, data requestData, funcs template.FuncMap) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid request %s template: %w", name, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render request %s: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// isToken reports whether s is a valid HTTP method or header name.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package poller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+)
+
+type FailureKind string
+
+const (
+	FailureKindConfig           FailureKind = "config"
+	FailureKindHTTP             FailureKind = "http"
+	FailureKindCircuitOpen      FailureKind = "circuit_open"
+	FailureKindParse            FailureKind = "parse"
+	FailureKindSelectorNotFound FailureKind = "selector_not_found"
+	FailureKindCurrency         FailureKind = "currency_mismatch"
+	FailureKindStorage          FailureKind = "storage"
+	// FailureKindCanceled is a link whose polling was cut short because the run was stopped.
+	FailureKindCanceled FailureKind = "canceled"
+)
+
+// LinkError explains why a single link could not be polled.
+type LinkError struct {
+	Kind FailureKind
+	Err  error
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
+
+func newLinkError(kind FailureKind, err error) *LinkError {
+	return &LinkError{Kind: kind, Err: err}
+}
+
+type LinkOutcome struct {
+	Link       link.LinkDescription
+	Price      price.PriceRecord
+	Err        *LinkError
+	StartedAt  time.Time
+	Latency    time.Duration
+	HTTPStatus int
+	// RawPrice is the string the parser extracted, before it was cleaned up and parsed.
+	RawPrice string
+	// MatchedRule is the index into Link.Extractions() of the rule that found RawPrice.
+	MatchedRule     int
+	MatchedSelector string
+	// ArchiveKeys are the keys of the archived responses received for the link, in the order they came in.
+	ArchiveKeys []string
+	// ResponseKey is the one of ArchiveKeys that the link's own request, rather than a step, received last.
+	ResponseKey string
+}
+
+func (o LinkOutcome) Attempt() pollrun.Attempt {
+	attempt := pollrun.Attempt{
+		LinkID:      o.Link.ID,
+		ProductName: o.Link.ProductName,
+		CountryCode: o.Link.CountryCode,
+		URL:         o.Link.URL,
+		HTTPStatus:  o.HTTPStatus,
+		Latency:     o.Latency,
+		RawValue:    o.RawPrice,
+		CreatedAt:   o.StartedAt,
+
+		MatchedRule:     o.MatchedRule,
+		MatchedSelector: o.MatchedSelector,
+		ArchiveKeys:     o.ArchiveKeys,
+		ResponseKey:     o.ResponseKey,
+	}
+
+	if o.Err != nil {
+		attempt.ErrorKind = string(o.Err.Kind)
+		attempt.Error = o.Err.Err.Error()
+	} else {
+		attempt.ParsedPrice = o.Price.Price.String()
+	}
+
+	return attempt
+}
+
+type Result struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Outcomes   []LinkOutcome
+}
+
+func (r Result) PollRun() pollrun.Run {
+	run := pollrun.Run{
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+
+	for _, outcome := range r.Outcomes {
+		if outcome.Err != nil {
+			run.Failed++
+		} else {
+			run.Succeeded++
+			if outcome.MatchedRule > 0 {
+				run.Fallbacks++
+			}
+		}
+		run.Attempts = append(run.Attempts, outcome.Attempt())
+	}
+
+	return run
+}
+
+func (r Result) Successes() []LinkOutcome {
+	return r.filter(func(outcome LinkOutcome) bool { return outcome.Err == nil })
+}
+
+func (r Result) Failures() []LinkOutcome {
+	return r.filter(func(outcome LinkOutcome) bool { return outcome.Err != nil })
+}
+
+func (r Result) filter(keep func(outcome LinkOutcome) bool) []LinkOutcome {
+	var outcomes []LinkOutcome
+	for _, outcome := range r.Outcomes {
+		if keep(outcome) {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
@@ -0,0 +1,27 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+// runSteps makes the requests of outcome.Link's steps in order and returns the values they extracted by step name.
+func (p *poller) runSteps(ctx context.Context, outcome *LinkOutcome) (map[string]string, *LinkError) {
+	linkDesc := outcome.Link
+
+	vars := make(map[string]string, len(linkDesc.Steps))
+	for _, step := range linkDesc.Steps {
+		spec := requestSpec{URL: step.URL, Config: step.Request}
+		value, _, linkErr := p.fetchValue(ctx, outcome, spec, vars, []link.Extraction{step.Extraction})
+		if linkErr != nil {
+			linkErr.Err = fmt.Errorf("step %s: %w", step.Name, linkErr.Err)
+			return nil, linkErr
+		}
+		vars[step.Name] = strings.TrimSpace(value)
+	}
+
+	return vars, nil
+}
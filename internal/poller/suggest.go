@@ -0,0 +1,40 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller/pricetext"
+	"github.com/turbak/bigmacindex/internal/suggest"
+)
+
+// SuggestSelectors fetches the page of linkDesc, after running its steps, and proposes selectors
+// that read seenPrice, the price a human sees on the page, from it.
+func (p *poller) SuggestSelectors(ctx context.Context, linkDesc link.LinkDescription, seenPrice string) ([]suggest.Suggestion, error) {
+	cur, err := p.currencyGetter.GetCurrencyByCountryCode(ctx, linkDesc.CountryCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve currency for country %s: %w", linkDesc.CountryCode, err)
+	}
+
+	outcome := LinkOutcome{Link: linkDesc, StartedAt: time.Now()}
+	vars, linkErr := p.runSteps(ctx, &outcome)
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	body, linkErr := p.fetchBody(ctx, &outcome, requestSpec{URL: linkDesc.URL, Config: linkDesc.Request}, vars)
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	return suggest.Suggest(body, suggest.Options{
+		Price:       seenPrice,
+		ProductName: linkDesc.ProductName,
+		Number: pricetext.Options{
+			MinorUnits: cur.MinorUnits,
+			Locale:     pricetext.LocaleForCountry(linkDesc.CountryCode),
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every",
+		"@every soon",
+		"@every 30s",
+		"@fortnightly",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) returned no error", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-03-14 is a Saturday.
+	from := time.Date(2026, 3, 14, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 3, 14, 10, 25, 0, 0, time.UTC)},
+		{"0,17 * * * *", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 6-9 * * *", time.Date(2026, 3, 15, 6, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * feb *", time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Either restricted day field may match.
+		{"0 0 20 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+		{"@every 90m", time.Date(2026, 3, 14, 11, 47, 30, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		sched, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.expr, err)
+			continue
+		}
+		if got := sched.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.expr, from, got, tt.want)
+		}
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	sched, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC) // 09:00 in Tokyo
+	want := time.Date(2026, 3, 15, 9, 0, 0, 0, tokyo)
+	if got := sched.Next(from.In(tokyo)); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
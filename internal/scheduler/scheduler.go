@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+type LinksLister interface {
+	ListLinks(ctx context.Context) ([]link.LinkDescription, error)
+}
+
+// RunFunc polls the links that are due.
+type RunFunc func(ctx context.Context, links []link.LinkDescription)
+
+type Config struct {
+	// Schedule applies to every link without its own schedule.
+	Schedule Schedule
+	// Jitter delays each run by a random duration up to this value.
+	Jitter time.Duration
+	// ShutdownTimeout is how long an in-flight run may continue after shutdown is requested.
+	ShutdownTimeout time.Duration
+	// RetryInterval is how long to wait before trying again when the links can't be listed.
+	RetryInterval time.Duration
+}
+
+type scheduler struct {
+	linksLister LinksLister
+	run         RunFunc
+	cfg         Config
+
+	running atomic.Bool
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(linksLister LinksLister, run RunFunc, cfg Config) *scheduler {
+	return &scheduler{
+		linksLister: linksLister,
+		run:         run,
+		cfg:         cfg,
+	}
+}
+
+// Run triggers runs until ctx is done, then waits up to the shutdown timeout for an in-flight run.
+// Links are re-listed before every run, so added links and changed schedules are picked up without
+// a restart. A run that comes due while the previous one is still going is skipped.
+func (s *scheduler) Run(ctx context.Context) error {
+	runCtx, cancelRuns := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRuns()
+
+	// Every link is scheduled from its own last run, so a frequent schedule doesn't keep pushing
+	// back the "@every" intervals of the others. Links that haven't run yet count from when they were
+	// first listed.
+	lastRuns := make(map[link.ID]time.Time)
+	for {
+		links, err := s.linksLister.ListLinks(ctx)
+		if err != nil {
+			log.Printf("failed to list links, retrying in %s: %v", s.cfg.RetryInterval, err)
+			if !sleep(ctx, s.cfg.RetryInterval) {
+				break
+			}
+			continue
+		}
+		lastRuns = trackLinks(lastRuns, links, time.Now())
+
+		next, due := s.nextRun(lastRuns, links)
+		if next.IsZero() {
+			log.Printf("no link is ever due, checking again in %s", s.cfg.RetryInterval)
+			if !sleep(ctx, s.cfg.RetryInterval) {
+				break
+			}
+			continue
+		}
+
+		wait := time.Until(next)
+		if s.cfg.Jitter > 0 {
+			wait += rand.N(s.cfg.Jitter)
+		}
+		log.Printf("Next run of %d links at %s", len(due), time.Now().Add(wait).Format(time.DateTime))
+		if !sleep(ctx, wait) {
+			break
+		}
+		// Activations missed while the process was suspended are coalesced into this run.
+		ranAt := next
+		if missedSince := time.Now().Add(-time.Minute); ranAt.Before(missedSince) {
+			ranAt = missedSince
+		}
+		for _, linkDesc := range due {
+			lastRuns[linkDesc.ID] = ranAt
+		}
+
+		if !s.running.CompareAndSwap(false, true) {
+			log.Printf("Skipping run scheduled for %s: the previous run is still in progress", next.Format(time.DateTime))
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.running.Store(false)
+			s.run(runCtx, due)
+		}()
+	}
+
+	return s.shutdown(cancelRuns)
+}
+
+func (s *scheduler) shutdown(cancelRuns context.CancelFunc) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	if s.running.Load() {
+		log.Printf("Waiting up to %s for the in-flight run to finish", s.cfg.ShutdownTimeout)
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(s.cfg.ShutdownTimeout):
+		cancelRuns()
+		<-done
+		return context.DeadlineExceeded
+	}
+}
+
+// nextRun returns the earliest activation across the global and per-link schedules, with the links
+// due at that time. Each link's schedule is followed from its entry in lastRuns.
+// Links with an invalid schedule fall back to the global one.
+func (s *scheduler) nextRun(lastRuns map[link.ID]time.Time, links []link.LinkDescription) (time.Time, []link.LinkDescription) {
+	schedules := make(map[string]Schedule)
+
+	var (
+		next time.Time
+		due  []link.LinkDescription
+	)
+	for _, linkDesc := range links {
+		sched, ok := schedules[linkDesc.Schedule]
+		if !ok {
+			sched = s.cfg.Schedule
+			if linkDesc.Schedule != "" {
+				parsed, err := Parse(linkDesc.Schedule)
+				if err != nil {
+					log.Printf("invalid schedule %q for link #%d, using the global schedule: %v", linkDesc.Schedule, linkDesc.ID, err)
+				} else {
+					sched = parsed
+				}
+			}
+			schedules[linkDesc.Schedule] = sched
+		}
+
+		linkNext := sched.Next(lastRuns[linkDesc.ID])
+		switch {
+		case linkNext.IsZero():
+		case next.IsZero() || linkNext.Before(next):
+			next = linkNext
+			due = []link.LinkDescription{linkDesc}
+		case linkNext.Equal(next):
+			due = append(due, linkDesc)
+		}
+	}
+
+	return next, due
+}
+
+// trackLinks returns the last runs of the listed links, starting newly listed ones at now
+// and forgetting deleted ones.
+func trackLinks(lastRuns map[link.ID]time.Time, links []link.LinkDescription, now time.Time) map[link.ID]time.Time {
+	tracked := make(map[link.ID]time.Time, len(links))
+	for _, linkDesc := range links {
+		last, ok := lastRuns[linkDesc.ID]
+		if !ok {
+			last = now
+		}
+		tracked[linkDesc.ID] = last
+	}
+	return tracked
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+func TestNextRunMixedSchedules(t *testing.T) {
+	global, err := Parse("@daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewScheduler(nil, nil, Config{Schedule: global})
+
+	links := []link.LinkDescription{
+		{ID: 1, Schedule: "@every 1h"},
+		{ID: 2, Schedule: "*/10 * * * *"},
+		{ID: 3, Schedule: "@every 25m"},
+		{ID: 4},
+	}
+
+	start := time.Date(2026, 3, 14, 10, 5, 0, 0, time.UTC)
+	lastRuns := trackLinks(nil, links, start)
+
+	runs := make(map[link.ID][]time.Time)
+	for {
+		next, due := s.nextRun(lastRuns, links)
+		if next.After(start.Add(3 * time.Hour)) {
+			break
+		}
+		for _, linkDesc := range due {
+			runs[linkDesc.ID] = append(runs[linkDesc.ID], next)
+			lastRuns[linkDesc.ID] = next
+		}
+	}
+
+	tests := []struct {
+		id        link.ID
+		wantRuns  int
+		wantFirst time.Time
+	}{
+		// The ten-minute link fires more often, but doesn't hold back the "@every" ones.
+		{1, 3, start.Add(time.Hour)},
+		{2, 18, time.Date(2026, 3, 14, 10, 10, 0, 0, time.UTC)},
+		{3, 7, start.Add(25 * time.Minute)},
+		{4, 0, time.Time{}},
+	}
+	for _, tt := range tests {
+		got := runs[tt.id]
+		if len(got) != tt.wantRuns {
+			t.Errorf("link #%d ran %d times, want %d: %v", tt.id, len(got), tt.wantRuns, got)
+			continue
+		}
+		if len(got) > 0 && !got[0].Equal(tt.wantFirst) {
+			t.Errorf("link #%d first ran at %s, want %s", tt.id, got[0], tt.wantFirst)
+		}
+	}
+}
+
+func TestTrackLinks(t *testing.T) {
+	earlier := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	now := earlier.Add(time.Hour)
+
+	got := trackLinks(map[link.ID]time.Time{1: earlier, 2: earlier}, []link.LinkDescription{{ID: 1}, {ID: 3}}, now)
+	want := map[link.ID]time.Time{1: earlier, 3: now}
+	if len(got) != len(want) {
+		t.Fatalf("trackLinks = %v, want %v", got, want)
+	}
+	for id, last := range want {
+		if !got[id].Equal(last) {
+			t.Errorf("trackLinks[%d] = %s, want %s", id, got[id], last)
+		}
+	}
+}
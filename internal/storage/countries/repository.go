@@ -0,0 +1,60 @@
+package countries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/turbak/bigmacindex/internal/domain/country"
+)
+
+const tableName = "countries"
+
+type repository struct {
+	db squirrel.StatementBuilderType
+}
+
+func NewRepository(db *sql.DB) *repository {
+	dbCache := squirrel.NewStmtCache(db)
+	sqDB := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question).RunWith(dbCache)
+	return &repository{
+		db: sqDB,
+	}
+}
+
+func (r *repository) ListCountries(ctx context.Context) ([]country.Country, error) {
+	rows, err := r.db.Select("code", "name", "currency_code").
+		From(tableName).
+		OrderBy("name").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countries []country.Country
+	for rows.Next() {
+		var c country.Country
+		err := rows.Scan(&c.Code, &c.Name, &c.CurrencyCode)
+		if err != nil {
+			return nil, err
+		}
+		countries = append(countries, c)
+	}
+	return countries, rows.Err()
+}
+
+func (r *repository) GetCountry(ctx context.Context, code string) (country.Country, error) {
+	row := r.db.Select("code", "name", "currency_code").
+		From(tableName).
+		Where(squirrel.Eq{"code": code}).
+		QueryRowContext(ctx)
+
+	var c country.Country
+	err := row.Scan(&c.Code, &c.Name, &c.CurrencyCode)
+	if err != nil {
+		return country.Country{}, err
+	}
+
+	return c, nil
+}
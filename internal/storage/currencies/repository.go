@@ -0,0 +1,120 @@
+package currencies
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/turbak/bigmacindex/internal/domain/currency"
+)
+
+const (
+	tableName        = "currencies"
+	symbolsTableName = "currency_symbols"
+	countriesTable   = "countries"
+)
+
+type repository struct {
+	db squirrel.StatementBuilderType
+}
+
+func NewRepository(db *sql.DB) *repository {
+	dbCache := squirrel.NewStmtCache(db)
+	sqDB := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question).RunWith(dbCache)
+	return &repository{
+		db: sqDB,
+	}
+}
+
+func (r *repository) ListCurrencies(ctx context.Context) ([]currency.Currency, error) {
+	rows, err := r.db.Select("code", "name", "minor_units").
+		From(tableName).
+		OrderBy("code").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []currency.Currency
+	for rows.Next() {
+		var cur currency.Currency
+		err := rows.Scan(&cur.Code, &cur.Name, &cur.MinorUnits)
+		if err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, cur)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	symbols, err := r.listSymbols(ctx, squirrel.Eq{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range currencies {
+		currencies[i].Symbols = symbols[currencies[i].Code]
+	}
+
+	return currencies, nil
+}
+
+func (r *repository) GetCurrency(ctx context.Context, code string) (currency.Currency, error) {
+	row := r.db.Select("code", "name", "minor_units").
+		From(tableName).
+		Where(squirrel.Eq{"code": code}).
+		QueryRowContext(ctx)
+
+	return r.scanCurrency(ctx, row)
+}
+
+func (r *repository) GetCurrencyByCountryCode(ctx context.Context, countryCode string) (currency.Currency, error) {
+	row := r.db.Select("c.code", "c.name", "c.minor_units").
+		From(tableName + " c").
+		Join(countriesTable + " co ON co.currency_code = c.code").
+		Where(squirrel.Eq{"co.code": countryCode}).
+		QueryRowContext(ctx)
+
+	return r.scanCurrency(ctx, row)
+}
+
+func (r *repository) scanCurrency(ctx context.Context, row squirrel.RowScanner) (currency.Currency, error) {
+	var cur currency.Currency
+	err := row.Scan(&cur.Code, &cur.Name, &cur.MinorUnits)
+	if err != nil {
+		return currency.Currency{}, err
+	}
+
+	symbols, err := r.listSymbols(ctx, squirrel.Eq{"currency_code": cur.Code})
+	if err != nil {
+		return currency.Currency{}, err
+	}
+	cur.Symbols = symbols[cur.Code]
+
+	return cur, nil
+}
+
+func (r *repository) listSymbols(ctx context.Context, where squirrel.Eq) (map[string][]string, error) {
+	rows, err := r.db.Select("currency_code", "symbol").
+		From(symbolsTableName).
+		Where(where).
+		OrderBy("currency_code", "symbol").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	symbols := make(map[string][]string)
+	for rows.Next() {
+		var code, symbol string
+		err := rows.Scan(&code, &symbol)
+		if err != nil {
+			return nil, err
+		}
+		symbols[code] = append(symbols[code], symbol)
+	}
+	return symbols, rows.Err()
+}
@@ -0,0 +1,139 @@
+package fxrates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+)
+
+const (
+	tableName = "fx_rates"
+	// upsertBatchSize keeps multi-row inserts under SQLite's bound parameter limit.
+	upsertBatchSize = 500
+)
+
+type repository struct {
+	db squirrel.StatementBuilderType
+}
+
+func NewRepository(db *sql.DB) *repository {
+	dbCache := squirrel.NewStmtCache(db)
+	sqDB := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question).RunWith(dbCache)
+	return &repository{
+		db: sqDB,
+	}
+}
+
+func (r *repository) UpsertRates(ctx context.Context, rates []fxrate.Rate) (int, error) {
+	var upserted int
+	for start := 0; start < len(rates); start += upsertBatchSize {
+		end := min(start+upsertBatchSize, len(rates))
+
+		query := r.db.Insert(tableName).Columns("date", "base", "quote", "rate")
+		for _, rate := range rates[start:end] {
+			query = query.Values(rate.Date, rate.Base, rate.Quote, rate.Rate)
+		}
+
+		_, err := query.
+			SuffixExpr(squirrel.Expr(` ON CONFLICT(date, base, quote) DO UPDATE SET rate = excluded.rate`)).
+			ExecContext(ctx)
+		if err != nil {
+			return upserted, err
+		}
+
+		upserted += end - start
+	}
+
+	return upserted, nil
+}
+
+// GetRate returns the base/quote rate in effect on date: the latest rate published on or before it.
+// A rate is derived from its inverse or crossed through a common base currency (e.g. EUR for ECB rates)
+// when no direct quote is stored, preferring whichever candidate is the most recent.
+func (r *repository) GetRate(ctx context.Context, date, base, quote string) (fxrate.Rate, error) {
+	if base == quote {
+		return fxrate.Rate{Date: date, Base: base, Quote: quote, Rate: 1}, nil
+	}
+
+	direct, err := r.getDirectRate(ctx, date, base, quote)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fxrate.Rate{}, err
+	}
+	directFound := err == nil
+
+	cross, err := r.getCrossRate(ctx, date, base, quote)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fxrate.Rate{}, err
+	}
+	crossFound := err == nil
+
+	switch {
+	case directFound && (!crossFound || direct.Date >= cross.Date):
+		return direct, nil
+	case crossFound:
+		return cross, nil
+	default:
+		return fxrate.Rate{}, sql.ErrNoRows
+	}
+}
+
+func (r *repository) getDirectRate(ctx context.Context, date, base, quote string) (fxrate.Rate, error) {
+	row := r.db.Select("id", "date", "base", "quote", "rate").
+		From(tableName).
+		Where(squirrel.Or{
+			squirrel.Eq{"base": base, "quote": quote},
+			squirrel.Eq{"base": quote, "quote": base},
+		}).
+		Where(squirrel.LtOrEq{"date": date}).
+		OrderBy("date DESC").
+		OrderByClause("base = ? DESC", base).
+		Limit(1).
+		QueryRowContext(ctx)
+
+	var rate fxrate.Rate
+	err := row.Scan(&rate.ID, &rate.Date, &rate.Base, &rate.Quote, &rate.Rate)
+	if err != nil {
+		return fxrate.Rate{}, err
+	}
+
+	if rate.Base != base {
+		rate = fxrate.Rate{
+			Date:  rate.Date,
+			Base:  base,
+			Quote: quote,
+			Rate:  1 / rate.Rate,
+		}
+	}
+
+	return rate, nil
+}
+
+func (r *repository) getCrossRate(ctx context.Context, date, base, quote string) (fxrate.Rate, error) {
+	row := r.db.Select("b.date", "b.rate", "q.rate").
+		From(tableName + " b").
+		Join(tableName + " q ON q.base = b.base AND q.date = b.date").
+		Where(squirrel.Eq{"b.quote": base, "q.quote": quote}).
+		Where(squirrel.LtOrEq{"b.date": date}).
+		OrderBy("b.date DESC").
+		Limit(1).
+		QueryRowContext(ctx)
+
+	var (
+		rateDate            string
+		baseRate, quoteRate float64
+	)
+	err := row.Scan(&rateDate, &baseRate, &quoteRate)
+	if err != nil {
+		return fxrate.Rate{}, err
+	}
+
+	return fxrate.Rate{
+		Date:  rateDate,
+		Base:  base,
+		Quote: quote,
+		Rate:  quoteRate / baseRate,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package fxrates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/fxrate"
+	"github.com/turbak/bigmacindex/internal/storage/storagetest"
+)
+
+func TestGetRate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(storagetest.Open(t, 5))
+
+	_, err := repo.UpsertRates(ctx, []fxrate.Rate{
+		{Date: "2026-01-02", Base: "EUR", Quote: "USD", Rate: 1.25},
+		{Date: "2026-01-02", Base: "EUR", Quote: "JPY", Rate: 150},
+		{Date: "2026-01-05", Base: "EUR", Quote: "USD", Rate: 1.20},
+		{Date: "2026-01-05", Base: "EUR", Quote: "JPY", Rate: 156},
+		{Date: "2026-01-03", Base: "USD", Quote: "CHF", Rate: 0.8},
+		// A direct quote older than the cross rate loses to it.
+		{Date: "2025-12-01", Base: "USD", Quote: "JPY", Rate: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		date        string
+		base, quote string
+		wantDate    string
+		wantRate    float64
+		wantErr     error
+	}{
+		{"same currency", "2026-01-05", "USD", "USD", "2026-01-05", 1, nil},
+		{"direct", "2026-01-05", "EUR", "USD", "2026-01-05", 1.20, nil},
+		{"latest on or before the date", "2026-01-04", "EUR", "USD", "2026-01-02", 1.25, nil},
+		{"inverse", "2026-01-05", "USD", "EUR", "2026-01-05", 1 / 1.20, nil},
+		{"cross via the common base beats an older direct quote", "2026-01-05", "USD", "JPY", "2026-01-05", 130, nil},
+		{"direct without a cross rate", "2026-01-03", "USD", "CHF", "2026-01-03", 0.8, nil},
+		{"direct inverse", "2026-01-03", "CHF", "USD", "2026-01-03", 1.25, nil},
+		{"before any rate", "2025-01-01", "EUR", "USD", "", 0, sql.ErrNoRows},
+		{"unknown currency", "2026-01-05", "USD", "GBP", "", 0, sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetRate(ctx, tt.date, tt.base, tt.quote)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetRate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRate() returned error: %v", err)
+			}
+			if got.Base != tt.base || got.Quote != tt.quote || got.Date != tt.wantDate || math.Abs(got.Rate-tt.wantRate) > 1e-9 {
+				t.Errorf("GetRate() = %+v, want %s/%s %v on %s", got, tt.base, tt.quote, tt.wantRate, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestUpsertRatesReplaces(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(storagetest.Open(t, 5))
+
+	for _, rate := range []float64{1.1, 1.2} {
+		if _, err := repo.UpsertRates(ctx, []fxrate.Rate{{Date: "2026-01-02", Base: "EUR", Quote: "USD", Rate: rate}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := repo.GetRate(ctx, "2026-01-02", "EUR", "USD")
+	if err != nil || got.Rate != 1.2 {
+		t.Errorf("GetRate() = %+v, %v, want the replaced rate 1.2", got, err)
+	}
+}
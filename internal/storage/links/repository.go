@@ -3,6 +3,8 @@ package links
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/turbak/bigmacindex/internal/domain/link"
@@ -10,6 +12,8 @@ import (
 
 const tableName = "links"
 
+var columns = []string{"id", "url", "link_type", "price_selector", "country_code", "product_name", "schedule", "request_config", "steps", "fallbacks"}
+
 type repository struct {
 	db squirrel.StatementBuilderType
 }
@@ -27,9 +31,14 @@ func (r *repository) DeleteLink(ctx context.Context, ID link.ID) error {
 }
 
 func (r *repository) AddLink(ctx context.Context, linkDesc link.LinkDescription) (link.LinkDescription, error) {
+	requestConfig, steps, fallbacks, err := marshalJSONColumns(linkDesc)
+	if err != nil {
+		return link.LinkDescription{}, err
+	}
+
 	res, err := r.db.Insert(tableName).
-		Columns("url", "link_type", "price_selector", "country_code", "product_name").
-		Values(linkDesc.URL, linkDesc.LinkType, linkDesc.PriceSelector, linkDesc.CountryCode, linkDesc.ProductName).
+		Columns("url", "link_type", "price_selector", "country_code", "product_name", "schedule", "request_config", "steps", "fallbacks").
+		Values(linkDesc.URL, linkDesc.LinkType, linkDesc.PriceSelector, linkDesc.CountryCode, linkDesc.ProductName, linkDesc.Schedule, requestConfig, steps, fallbacks).
 		ExecContext(ctx)
 	if err != nil {
 		return link.LinkDescription{}, err
@@ -44,7 +53,7 @@ func (r *repository) AddLink(ctx context.Context, linkDesc link.LinkDescription)
 }
 
 func (r *repository) ListLinks(ctx context.Context) ([]link.LinkDescription, error) {
-	rows, err := r.db.Select("id", "url", "link_type", "price_selector", "country_code", "product_name").
+	rows, err := r.db.Select(columns...).
 		From(tableName).
 		QueryContext(ctx)
 	if err != nil {
@@ -54,8 +63,7 @@ func (r *repository) ListLinks(ctx context.Context) ([]link.LinkDescription, err
 
 	var linkDescs []link.LinkDescription
 	for rows.Next() {
-		var linkDesc link.LinkDescription
-		err := rows.Scan(&linkDesc.ID, &linkDesc.URL, &linkDesc.LinkType, &linkDesc.PriceSelector, &linkDesc.CountryCode, &linkDesc.ProductName)
+		linkDesc, err := scanLink(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -65,12 +73,21 @@ func (r *repository) ListLinks(ctx context.Context) ([]link.LinkDescription, err
 }
 
 func (r *repository) UpdateLink(ctx context.Context, linkDesc link.LinkDescription) (link.LinkDescription, error) {
-	_, err := r.db.Update(tableName).
+	requestConfig, steps, fallbacks, err := marshalJSONColumns(linkDesc)
+	if err != nil {
+		return link.LinkDescription{}, err
+	}
+
+	_, err = r.db.Update(tableName).
 		Set("url", linkDesc.URL).
 		Set("link_type", linkDesc.LinkType).
 		Set("price_selector", linkDesc.PriceSelector).
 		Set("country_code", linkDesc.CountryCode).
 		Set("product_name", linkDesc.ProductName).
+		Set("schedule", linkDesc.Schedule).
+		Set("request_config", requestConfig).
+		Set("steps", steps).
+		Set("fallbacks", fallbacks).
 		Where(squirrel.Eq{"id": linkDesc.ID}).
 		ExecContext(ctx)
 	if err != nil {
@@ -81,16 +98,72 @@ func (r *repository) UpdateLink(ctx context.Context, linkDesc link.LinkDescripti
 }
 
 func (r *repository) GetLinkByID(ctx context.Context, ID link.ID) (link.LinkDescription, error) {
-	row := r.db.Select("id", "url", "link_type", "price_selector", "country_code", "product_name").
+	row := r.db.Select(columns...).
 		From(tableName).
 		Where(squirrel.Eq{"id": ID}).
 		QueryRowContext(ctx)
 
-	var linkDesc link.LinkDescription
-	err := row.Scan(&linkDesc.ID, &linkDesc.URL, &linkDesc.LinkType, &linkDesc.PriceSelector, &linkDesc.CountryCode, &linkDesc.ProductName)
+	return scanLink(row)
+}
+
+func scanLink(row squirrel.RowScanner) (link.LinkDescription, error) {
+	var (
+		linkDesc      link.LinkDescription
+		requestConfig string
+		steps         string
+		fallbacks     string
+	)
+	err := row.Scan(&linkDesc.ID, &linkDesc.URL, &linkDesc.LinkType, &linkDesc.PriceSelector, &linkDesc.CountryCode, &linkDesc.ProductName, &linkDesc.Schedule, &requestConfig, &steps, &fallbacks)
 	if err != nil {
 		return link.LinkDescription{}, err
 	}
 
+	columns := []struct {
+		name  string
+		value string
+		dst   any
+	}{
+		{"request config", requestConfig, &linkDesc.Request},
+		{"steps", steps, &linkDesc.Steps},
+		{"fallbacks", fallbacks, &linkDesc.Fallbacks},
+	}
+	for _, column := range columns {
+		if column.value == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(column.value), column.dst); err != nil {
+			return link.LinkDescription{}, fmt.Errorf("invalid %s of link #%d: %w", column.name, linkDesc.ID, err)
+		}
+	}
+
 	return linkDesc, nil
 }
+
+// marshalJSONColumns encodes the request config, steps and fallbacks of linkDesc, storing defaults as empty strings.
+func marshalJSONColumns(linkDesc link.LinkDescription) (requestConfig, steps, fallbacks string, err error) {
+	if !linkDesc.Request.IsZero() {
+		if requestConfig, err = marshalJSON("request config", linkDesc.Request); err != nil {
+			return "", "", "", err
+		}
+	}
+	if len(linkDesc.Steps) > 0 {
+		if steps, err = marshalJSON("steps", linkDesc.Steps); err != nil {
+			return "", "", "", err
+		}
+	}
+	if len(linkDesc.Fallbacks) > 0 {
+		if fallbacks, err = marshalJSON("fallbacks", linkDesc.Fallbacks); err != nil {
+			return "", "", "", err
+		}
+	}
+
+	return requestConfig, steps, fallbacks, nil
+}
+
+func marshalJSON(name string, value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,185 @@
+package pollruns
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/turbak/bigmacindex/internal/domain/pollrun"
+)
+
+const (
+	tableName         = "poll_runs"
+	attemptsTableName = "poll_attempts"
+)
+
+var attemptColumns = []string{
+	"id", "run_id", "link_id", "product_name", "country_code", "url", "http_status",
+	"latency_ms", "raw_value", "parsed_price", "error_kind", "error", "created_at", "matched_rule", "matched_selector",
+	"archive_keys", "response_key",
+}
+
+type repository struct {
+	db    squirrel.StatementBuilderType
+	sqlDB *sql.DB
+}
+
+func NewRepository(db *sql.DB) *repository {
+	dbCache := squirrel.NewStmtCache(db)
+	sqDB := squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question).RunWith(dbCache)
+	return &repository{
+		db:    sqDB,
+		sqlDB: db,
+	}
+}
+
+// SaveRun saves the run along with its attempts, or nothing at all if any of them fails to save.
+func (r *repository) SaveRun(ctx context.Context, run pollrun.Run) (pollrun.Run, error) {
+	tx, err := r.sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return pollrun.Run{}, err
+	}
+	defer tx.Rollback()
+
+	txDB := r.db.RunWith(tx)
+
+	res, err := txDB.Insert(tableName).
+		Columns("started_at", "finished_at", "succeeded", "failed", "fallbacks").
+		Values(run.StartedAt, run.FinishedAt, run.Succeeded, run.Failed, run.Fallbacks).
+		ExecContext(ctx)
+	if err != nil {
+		return pollrun.Run{}, err
+	}
+
+	ID, err := res.LastInsertId()
+	if err != nil {
+		return pollrun.Run{}, err
+	}
+	run.ID = pollrun.ID(ID)
+
+	for i, attempt := range run.Attempts {
+		attempt.RunID = run.ID
+		res, err := txDB.Insert(attemptsTableName).
+			Columns(attemptColumns[1:]...).
+			Values(attempt.RunID, attempt.LinkID, attempt.ProductName, attempt.CountryCode, attempt.URL, attempt.HTTPStatus,
+				attempt.Latency.Milliseconds(), attempt.RawValue, attempt.ParsedPrice, attempt.ErrorKind, attempt.Error, attempt.CreatedAt,
+				attempt.MatchedRule, attempt.MatchedSelector, strings.Join(attempt.ArchiveKeys, " "), attempt.ResponseKey).
+			ExecContext(ctx)
+		if err != nil {
+			return pollrun.Run{}, err
+		}
+
+		attemptID, err := res.LastInsertId()
+		if err != nil {
+			return pollrun.Run{}, err
+		}
+		attempt.ID = pollrun.ID(attemptID)
+		run.Attempts[i] = attempt
+	}
+
+	if err := tx.Commit(); err != nil {
+		return pollrun.Run{}, err
+	}
+
+	return run, nil
+}
+
+func (r *repository) ListRuns(ctx context.Context, limit uint64) ([]pollrun.Run, error) {
+	rows, err := r.db.Select("id", "started_at", "finished_at", "succeeded", "failed", "fallbacks").
+		From(tableName).
+		OrderBy("started_at DESC").
+		Limit(limit).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []pollrun.Run
+	for rows.Next() {
+		var run pollrun.Run
+		err := rows.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Succeeded, &run.Failed, &run.Fallbacks)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *repository) GetRunByID(ctx context.Context, ID pollrun.ID) (pollrun.Run, error) {
+	row := r.db.Select("id", "started_at", "finished_at", "succeeded", "failed", "fallbacks").
+		From(tableName).
+		Where(squirrel.Eq{"id": ID}).
+		QueryRowContext(ctx)
+
+	var run pollrun.Run
+	err := row.Scan(&run.ID, &run.StartedAt, &run.FinishedAt, &run.Succeeded, &run.Failed, &run.Fallbacks)
+	if err != nil {
+		return pollrun.Run{}, err
+	}
+
+	run.Attempts, err = r.listAttempts(ctx, squirrel.Eq{"run_id": ID}, "error_kind = ''", "id")
+	if err != nil {
+		return pollrun.Run{}, err
+	}
+
+	return run, nil
+}
+
+// ListArchivedAttempts returns the attempts made since the given time whose link's own response was archived, oldest first.
+func (r *repository) ListArchivedAttempts(ctx context.Context, since time.Time) ([]pollrun.Attempt, error) {
+	return r.listAttempts(ctx, squirrel.And{
+		squirrel.NotEq{"response_key": ""},
+		squirrel.GtOrEq{"created_at": since},
+	}, "created_at", "id")
+}
+
+// ListLatestAttempts returns the latest perLink attempts of every link, newest first, leaving out
+// the attempts that failed with one of skipErrorKinds.
+func (r *repository) ListLatestAttempts(ctx context.Context, perLink int, skipErrorKinds ...string) ([]pollrun.Attempt, error) {
+	latest := r.db.Select("*", "ROW_NUMBER() OVER (PARTITION BY link_id ORDER BY created_at DESC, id DESC) AS n").
+		From(attemptsTableName).
+		Where(squirrel.NotEq{"error_kind": skipErrorKinds})
+
+	return r.queryAttempts(ctx, r.db.Select(attemptColumns...).
+		FromSelect(latest, "latest").
+		Where(squirrel.LtOrEq{"n": perLink}).
+		OrderBy("created_at DESC", "id DESC"))
+}
+
+func (r *repository) listAttempts(ctx context.Context, where squirrel.Sqlizer, orderBy ...string) ([]pollrun.Attempt, error) {
+	return r.queryAttempts(ctx, r.db.Select(attemptColumns...).
+		From(attemptsTableName).
+		Where(where).
+		OrderBy(orderBy...))
+}
+
+func (r *repository) queryAttempts(ctx context.Context, query squirrel.SelectBuilder) ([]pollrun.Attempt, error) {
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []pollrun.Attempt
+	for rows.Next() {
+		var (
+			attempt     pollrun.Attempt
+			latencyMs   int64
+			archiveKeys string
+		)
+		err := rows.Scan(&attempt.ID, &attempt.RunID, &attempt.LinkID, &attempt.ProductName, &attempt.CountryCode, &attempt.URL,
+			&attempt.HTTPStatus, &latencyMs, &attempt.RawValue, &attempt.ParsedPrice, &attempt.ErrorKind, &attempt.Error, &attempt.CreatedAt,
+			&attempt.MatchedRule, &attempt.MatchedSelector, &archiveKeys, &attempt.ResponseKey)
+		if err != nil {
+			return nil, err
+		}
+		attempt.Latency = time.Duration(latencyMs) * time.Millisecond
+		attempt.ArchiveKeys = strings.Fields(archiveKeys)
+		attempts = append(attempts, attempt)
+	}
+	return attempts, rows.Err()
+}
@@ -3,6 +3,7 @@ package prices
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/turbak/bigmacindex/internal/domain/price"
@@ -22,50 +23,142 @@ func NewRepository(db *sql.DB) *repository {
 	}
 }
 
-func (r *repository) ListPrices(ctx context.Context) ([]price.PriceRecord, error) {
-	rows, err := r.db.Select("id", "product_name", "price", "price_cents", "currency", "country_code", "created_date").
+func (r *repository) ListPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error) {
+	rows, err := r.db.Select("id", "product_name", "amount", "minor_units", "currency", "country_code", "created_date", "source", "source_ref").
 		From(tableName).
+		Where(filterConditions("", filter)).
+		OrderBy("created_date DESC", "country_code", "product_name").
 		QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var priceRecs []price.PriceRecord
-	for rows.Next() {
-		var priceRec price.PriceRecord
-		err := rows.Scan(&priceRec.ID, &priceRec.ProductName, &priceRec.Price, &priceRec.PriceCents, &priceRec.Currency, &priceRec.CountryCode, &priceRec.CreatedDate)
-		if err != nil {
-			return nil, err
-		}
-		priceRecs = append(priceRecs, priceRec)
+	return scanPrices(rows)
+}
+
+// ListLatestPrices returns the most recent price of every product in every country matching filter.
+func (r *repository) ListLatestPrices(ctx context.Context, filter price.Filter) ([]price.PriceRecord, error) {
+	latest := r.db.Select("MAX(l.created_date)").
+		From(tableName + " l").
+		Where("l.product_name = p.product_name AND l.country_code = p.country_code").
+		Where(filterConditions("l.", price.Filter{From: filter.From, To: filter.To}))
+
+	latestSQL, latestArgs, err := latest.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Select("p.id", "p.product_name", "p.amount", "p.minor_units", "p.currency", "p.country_code", "p.created_date", "p.source", "p.source_ref").
+		From(tableName+" p").
+		Where(filterConditions("p.", price.Filter{CountryCode: filter.CountryCode, ProductName: filter.ProductName})).
+		Where("p.created_date = ("+latestSQL+")", latestArgs...).
+		OrderBy("p.country_code", "p.product_name").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return priceRecs, nil
+	defer rows.Close()
+
+	return scanPrices(rows)
 }
 
 func (r *repository) UpsertPrice(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, error) {
-	// Squirrel doesn't have built-in UPSERT support, so we'll use raw SQL for the ON CONFLICT part
-	res, err := r.db.Insert(tableName).
-		Columns("product_name", "price", "price_cents", "currency", "country_code", "created_date").
-		Values(priceRec.ProductName, priceRec.Price, priceRec.PriceCents, priceRec.Currency, priceRec.CountryCode, priceRec.CreatedDate).
+	// Squirrel doesn't have built-in UPSERT support, so we'll use raw SQL for the ON CONFLICT part.
+	// LastInsertId isn't the updated row's id when the update branch runs, so the id is returned instead.
+	var ID int64
+	err := r.insertPrice(priceRec).
 		SuffixExpr(
 			squirrel.Expr(` ON CONFLICT(product_name, country_code, created_date) DO UPDATE SET
 									product_name = excluded.product_name,
-									price = excluded.price,
-									price_cents = excluded.price_cents,
-									currency = excluded.currency`),
+									amount = excluded.amount,
+									minor_units = excluded.minor_units,
+									currency = excluded.currency,
+									source = excluded.source,
+									source_ref = excluded.source_ref
+								RETURNING id`),
 		).
-		ExecContext(ctx)
+		QueryRowContext(ctx).
+		Scan(&ID)
 	if err != nil {
 		return price.PriceRecord{}, err
 	}
 
-	ID, err := res.LastInsertId()
+	priceRec.ID = price.ID(ID)
+
+	return priceRec, nil
+}
+
+// InsertBackfilledPrice saves a price read from an archived snapshot. It replaces an earlier backfilled
+// price of the same day but never a polled one, in which case it reports that nothing was saved.
+func (r *repository) InsertBackfilledPrice(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, bool, error) {
+	priceRec.Source = price.SourceBackfill
+	// LastInsertId isn't the replaced row's id when the update branch runs, so the id is returned instead;
+	// no row comes back when a polled price is kept.
+	var ID int64
+	err := r.insertPrice(priceRec).
+		SuffixExpr(
+			squirrel.Expr(` ON CONFLICT(product_name, country_code, created_date) DO UPDATE SET
+									amount = excluded.amount,
+									minor_units = excluded.minor_units,
+									currency = excluded.currency,
+									source_ref = excluded.source_ref
+								WHERE prices.source = ?
+								RETURNING id`, price.SourceBackfill),
+		).
+		QueryRowContext(ctx).
+		Scan(&ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return priceRec, false, nil
+	}
 	if err != nil {
-		return price.PriceRecord{}, err
+		return price.PriceRecord{}, false, err
 	}
-
 	priceRec.ID = price.ID(ID)
 
-	return priceRec, nil
+	return priceRec, true, nil
+}
+
+func (r *repository) insertPrice(priceRec price.PriceRecord) squirrel.InsertBuilder {
+	source := priceRec.Source
+	if source == "" {
+		source = price.SourcePoll
+	}
+
+	return r.db.Insert(tableName).
+		Columns("product_name", "amount", "minor_units", "currency", "country_code", "created_date", "source", "source_ref").
+		Values(priceRec.ProductName, priceRec.Price.Amount, priceRec.Price.MinorUnits, priceRec.Price.Currency, priceRec.CountryCode, priceRec.CreatedDate,
+			source, priceRec.SourceRef)
+}
+
+func filterConditions(prefix string, filter price.Filter) squirrel.And {
+	conds := squirrel.And{}
+	if filter.CountryCode != "" {
+		conds = append(conds, squirrel.Eq{prefix + "country_code": filter.CountryCode})
+	}
+	if filter.ProductName != "" {
+		conds = append(conds, squirrel.Eq{prefix + "product_name": filter.ProductName})
+	}
+	if filter.From != "" {
+		conds = append(conds, squirrel.GtOrEq{prefix + "created_date": filter.From})
+	}
+	if filter.To != "" {
+		conds = append(conds, squirrel.LtOrEq{prefix + "created_date": filter.To})
+	}
+
+	return conds
+}
+
+func scanPrices(rows *sql.Rows) ([]price.PriceRecord, error) {
+	var priceRecs []price.PriceRecord
+	for rows.Next() {
+		var priceRec price.PriceRecord
+		err := rows.Scan(&priceRec.ID, &priceRec.ProductName, &priceRec.Price.Amount, &priceRec.Price.MinorUnits, &priceRec.Price.Currency, &priceRec.CountryCode, &priceRec.CreatedDate,
+			&priceRec.Source, &priceRec.SourceRef)
+		if err != nil {
+			return nil, err
+		}
+		priceRecs = append(priceRecs, priceRec)
+	}
+	return priceRecs, rows.Err()
 }
@@ -0,0 +1,109 @@
+package prices
+
+import (
+	"context"
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/money"
+	"github.com/turbak/bigmacindex/internal/domain/price"
+	"github.com/turbak/bigmacindex/internal/storage/storagetest"
+)
+
+func TestMinorUnitsMigration(t *testing.T) {
+	db := storagetest.Open(t, 7)
+
+	_, err := db.Exec(`INSERT INTO prices (product_name, price, price_cents, currency, country_code, created_date) VALUES
+		('Big Mac', 5, 69, 'USD', 'US', '2024-01-01'),
+		('Big Mac', 5, 5, 'USD', 'US', '2024-01-02'),
+		('Big Mac', 390, 0, 'JPY', 'JP', '2024-01-01'),
+		('Big Mac', 1, 250, 'KWD', 'KW', '2024-01-01'),
+		('Big Mac', 1, 250, '', 'KW', '2024-01-02'),
+		('Big Mac', 450, 0, '', 'JP', '2024-01-02'),
+		('Big Mac', 7, 10, 'CHF', 'JP', '2024-01-03'),
+		('Big Mac', 3, 99, '', 'ZZ', '2024-01-01')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storagetest.Migrate(t, db, 8, 8)
+
+	tests := []struct {
+		country, date  string
+		wantAmount     int64
+		wantMinorUnits int
+		wantCurrency   string
+	}{
+		{"US", "2024-01-01", 569, 2, "USD"},
+		{"US", "2024-01-02", 505, 2, "USD"},
+		{"JP", "2024-01-01", 390, 0, "JPY"},
+		{"KW", "2024-01-01", 1250, 3, "KWD"},
+		// Prices without a currency take their country's.
+		{"KW", "2024-01-02", 1250, 3, "KWD"},
+		{"JP", "2024-01-02", 450, 0, "JPY"},
+		// A recorded currency wins over the country's.
+		{"JP", "2024-01-03", 710, 2, "CHF"},
+		// Without either, two minor units are assumed.
+		{"ZZ", "2024-01-01", 399, 2, ""},
+	}
+
+	for _, tt := range tests {
+		var (
+			amount     int64
+			minorUnits int
+			currency   string
+		)
+		err := db.QueryRow(`SELECT amount, minor_units, currency FROM prices WHERE country_code = ? AND created_date = ?`, tt.country, tt.date).
+			Scan(&amount, &minorUnits, &currency)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.country, tt.date, err)
+			continue
+		}
+		if amount != tt.wantAmount || minorUnits != tt.wantMinorUnits || currency != tt.wantCurrency {
+			t.Errorf("%s %s migrated to %d/%d %q, want %d/%d %q", tt.country, tt.date, amount, minorUnits, currency,
+				tt.wantAmount, tt.wantMinorUnits, tt.wantCurrency)
+		}
+	}
+}
+
+func TestUpsertPriceReturnsTheRowID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRepository(storagetest.Open(t, 14))
+
+	priceRec := func(date string, amount int64) price.PriceRecord {
+		return price.PriceRecord{ProductName: "Big Mac", CountryCode: "US", CreatedDate: date, Price: money.New(amount, "USD", 2)}
+	}
+
+	tests := []struct {
+		name   string
+		upsert func(context.Context, price.PriceRecord) (price.PriceRecord, error)
+		rec    price.PriceRecord
+		wantID price.ID
+	}{
+		{"polled", repo.UpsertPrice, priceRec("2026-01-01", 569), 1},
+		{"backfilled", repo.backfill, priceRec("2026-01-02", 559), 2},
+		{"polled again", repo.UpsertPrice, priceRec("2026-01-03", 579), 3},
+		{"polled over the first day", repo.UpsertPrice, priceRec("2026-01-01", 589), 1},
+		{"backfilled over the second day", repo.backfill, priceRec("2026-01-02", 549), 2},
+		{"polled over the backfilled day", repo.UpsertPrice, priceRec("2026-01-02", 599), 2},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.upsert(ctx, tt.rec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.ID != tt.wantID {
+			t.Errorf("%s: ID = %d, want %d", tt.name, got.ID, tt.wantID)
+		}
+	}
+
+	if _, inserted, err := repo.InsertBackfilledPrice(ctx, priceRec("2026-01-02", 500)); err != nil || inserted {
+		t.Errorf("InsertBackfilledPrice over a polled price = %v, %v, want it kept", inserted, err)
+	}
+}
+
+// backfill is InsertBackfilledPrice in the shape of UpsertPrice.
+func (r *repository) backfill(ctx context.Context, priceRec price.PriceRecord) (price.PriceRecord, error) {
+	saved, _, err := r.InsertBackfilledPrice(ctx, priceRec)
+	return saved, err
+}
@@ -0,0 +1,59 @@
+// Package storagetest opens databases for the storage tests, migrated with the goose migrations
+// the application runs on.
+package storagetest
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open returns an in-memory database with the migrations up to and including version applied.
+func Open(t testing.TB, version int) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a database of its own.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	Migrate(t, db, 1, version)
+	return db
+}
+
+// Migrate applies the Up sections of the migrations from version from to version to.
+func Migrate(t testing.TB, db *sql.DB, from, to int) {
+	t.Helper()
+
+	_, file, _, _ := runtime.Caller(0)
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		prefix, _, _ := strings.Cut(filepath.Base(path), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version < from || version > to {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+		if _, err := db.Exec(up); err != nil {
+			t.Fatalf("migration %s: %v", filepath.Base(path), err)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package suggest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller/parsers"
+	"golang.org/x/net/html"
+)
+
+// maxTextLen bounds the text nodes considered; longer ones are prose that merely mentions the price.
+const maxTextLen = 40
+
+var (
+	// cssIdentRe matches ids and class names usable in a CSS selector without escaping.
+	cssIdentRe = regexp.MustCompile(`^-?[A-Za-z_][-A-Za-z0-9_]*$`)
+	// assignmentRe matches the variable a script assigns its JSON to, e.g. `window.__INITIAL_STATE__ = {`.
+	assignmentRe = regexp.MustCompile(`^\s*(?:(?:var|let|const)\s+)?([A-Za-z_$][\w$.]*)\s*=\s*[{\[]`)
+	// labelRe matches a word labelling a price, e.g. "Цена" in "Цена: 199 ₽".
+	labelRe = regexp.MustCompile(`\p{L}{3,}`)
+)
+
+// priceAttributes are the attributes checked for a machine-readable price.
+var priceAttributes = []string{"content", "value", "data-price", "data-value", "data-amount"}
+
+// identifyingAttributes pick out an element by what it is rather than where it is.
+var identifyingAttributes = []string{"itemprop", "property", "name"}
+
+// skippedElements don't hold visible prices; scripts are searched for embedded JSON instead.
+var skippedElements = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "title": true}
+
+func (s *suggester) suggestStructured() {
+	if s.opts.ProductName != "" && s.add(link.LinkTypeStructured, s.opts.ProductName, 100, "schema.org offer for the product") {
+		return
+	}
+	s.add(link.LinkTypeStructured, "", 95, "first schema.org offer on the page")
+}
+
+func (s *suggester) suggestHTML(doc *html.Node) {
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.ElementNode:
+			if skippedElements[node.Data] {
+				return
+			}
+			for _, attr := range priceAttributes {
+				if htmlquery.ExistsAttr(node, attr) && s.matches(htmlquery.SelectAttr(node, attr)) {
+					s.suggestElement(node, attr)
+				}
+			}
+		case html.TextNode:
+			text := strings.TrimSpace(node.Data)
+			if text != "" && len([]rune(text)) <= maxTextLen && s.matches(text) && node.Parent != nil {
+				s.suggestElement(node.Parent, "")
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+}
+
+// suggestElement suggests CSS and XPath selectors for the text of node, or its attribute attr when set.
+func (s *suggester) suggestElement(node *html.Node, attr string) {
+	s.suggestCSS(node, attr)
+	s.suggestXPath(node, attr)
+}
+
+func (s *suggester) suggestCSS(node *html.Node, attr string) {
+	selector, score := cssSelector(node)
+	suffix := ""
+	if attr != "" {
+		suffix = "@" + attr
+	}
+
+	if selector != node.Data {
+		s.add(link.LinkTypeCSS, selector+suffix, score, "")
+	}
+	if id := ancestorID(node); cssIdentRe.MatchString(id) {
+		s.add(link.LinkTypeCSS, "#"+id+" "+selector+suffix, score-5, "")
+	}
+}
+
+// cssSelector describes node by its id, classes or identifying attributes.
+func cssSelector(node *html.Node) (string, int) {
+	if id := htmlquery.SelectAttr(node, "id"); cssIdentRe.MatchString(id) {
+		return "#" + id, 85
+	}
+
+	var sb strings.Builder
+	sb.WriteString(node.Data)
+	for _, class := range strings.Fields(htmlquery.SelectAttr(node, "class")) {
+		if cssIdentRe.MatchString(class) {
+			sb.WriteString("." + class)
+		}
+	}
+	for _, attr := range identifyingAttributes {
+		if value := htmlquery.SelectAttr(node, attr); value != "" && !strings.ContainsAny(value, `"\`) {
+			fmt.Fprintf(&sb, `[%s="%s"]`, attr, value)
+		}
+	}
+
+	selector := sb.String()
+	if strings.Contains(strings.ToLower(selector), "price") {
+		return selector, 75
+	}
+	return selector, 60
+}
+
+func (s *suggester) suggestXPath(node *html.Node, attr string) {
+	suffix := ""
+	if attr != "" {
+		suffix = "/@" + attr
+	}
+
+	if id := htmlquery.SelectAttr(node, "id"); id != "" {
+		if lit, ok := xpathLiteral(id); ok {
+			s.add(link.LinkTypeHTML, "//*[@id="+lit+"]"+suffix, 80, "")
+		}
+	}
+	for _, name := range append([]string{"class"}, identifyingAttributes...) {
+		value := htmlquery.SelectAttr(node, name)
+		if lit, ok := xpathLiteral(value); ok && value != "" {
+			score := 60
+			if strings.Contains(strings.ToLower(value), "price") {
+				score += 5
+			}
+			s.add(link.LinkTypeHTML, fmt.Sprintf("//%s[@%s=%s]%s", node.Data, name, lit, suffix), score, "")
+		}
+	}
+	if attr == "" {
+		s.suggestLabelXPath(node)
+	}
+
+	if anchor, path := idAnchoredPath(node); anchor != "" {
+		s.add(link.LinkTypeHTML, anchor+path+suffix, 55, "")
+	} else {
+		s.add(link.LinkTypeHTML, path+suffix, 30, "absolute path; breaks when the page layout changes")
+	}
+}
+
+// suggestLabelXPath anchors on the text labelling the price, either inside node ("Цена: 199 ₽")
+// or in the element right before it (<dt>Price</dt><dd>199</dd>).
+func (s *suggester) suggestLabelXPath(node *html.Node) {
+	if label := labelRe.FindString(ownText(node)); label != "" {
+		if lit, ok := xpathLiteral(label); ok {
+			s.add(link.LinkTypeHTML, fmt.Sprintf("//%s[contains(text(),%s)]", node.Data, lit), 70, "anchored on the price label")
+		}
+	}
+
+	prev := node.PrevSibling
+	for prev != nil && prev.Type != html.ElementNode {
+		prev = prev.PrevSibling
+	}
+	if prev == nil {
+		return
+	}
+	text := strings.TrimSpace(htmlquery.InnerText(prev))
+	if label := labelRe.FindString(text); label != "" && len([]rune(text)) <= maxTextLen && strings.IndexFunc(text, unicode.IsDigit) < 0 {
+		if lit, ok := xpathLiteral(label); ok {
+			s.add(link.LinkTypeHTML, fmt.Sprintf("//%s[contains(.,%s)]/following-sibling::%s[1]", prev.Data, lit, node.Data), 70, "anchored on the price label")
+		}
+	}
+}
+
+// suggestEmbeddedJSON looks for the price in JSON held by scripts, such as Next.js page data
+// or a `window.__INITIAL_STATE__ = {...}` assignment.
+func (s *suggester) suggestEmbeddedJSON(doc *html.Node) {
+	typeCounts := make(map[string]int)
+	for _, script := range htmlquery.Find(doc, "//script") {
+		scriptType := htmlquery.SelectAttr(script, "type")
+		typeCounts[scriptType]++
+		// JSON-LD is read by the structured data parser.
+		if strings.Contains(scriptType, "ld+json") {
+			continue
+		}
+
+		text := htmlquery.InnerText(script)
+		decoder := json.NewDecoder(strings.NewReader(parsers.UnwrapJSON(text)))
+		decoder.UseNumber()
+		var data any
+		if err := decoder.Decode(&data); err != nil {
+			continue
+		}
+
+		locator, score := scriptLocator(script, text, typeCounts[scriptType])
+		if locator == "" {
+			continue
+		}
+		for _, p := range s.jsonPaths(data) {
+			s.add(link.LinkTypeEmbeddedJSON, locator+" | "+p.path, p.score(score), p.note())
+		}
+	}
+}
+
+// scriptLocator finds a selector for script, which is the n-th script of its type on the page.
+func scriptLocator(script *html.Node, text string, n int) (string, int) {
+	if id := htmlquery.SelectAttr(script, "id"); cssIdentRe.MatchString(id) {
+		return "script#" + id, 75
+	}
+	if m := assignmentRe.FindStringSubmatch(text); m != nil {
+		if lit, ok := xpathLiteral(m[1]); ok {
+			return fmt.Sprintf("//script[contains(.,%s)]", lit), 70
+		}
+	}
+	if scriptType := htmlquery.SelectAttr(script, "type"); scriptType != "" {
+		if lit, ok := xpathLiteral(scriptType); ok {
+			return fmt.Sprintf("(//script[@type=%s])[%d]", lit, n), 50
+		}
+	}
+	return "", 0
+}
+
+// idAnchoredPath returns the XPath steps from node's nearest ancestor with an id down to node,
+// or from the document root when there is no such ancestor.
+func idAnchoredPath(node *html.Node) (string, string) {
+	var steps []string
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if n != node {
+			if lit, ok := xpathLiteral(htmlquery.SelectAttr(n, "id")); ok && htmlquery.SelectAttr(n, "id") != "" {
+				return "//*[@id=" + lit + "]", "/" + strings.Join(steps, "/")
+			}
+		}
+		steps = append([]string{pathStep(n)}, steps...)
+	}
+	return "", "/" + strings.Join(steps, "/")
+}
+
+// pathStep names node among its siblings, with a position only when siblings share its tag.
+func pathStep(node *html.Node) string {
+	position, count := 0, 0
+	if node.Parent != nil {
+		for sibling := node.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+			if sibling.Type == html.ElementNode && sibling.Data == node.Data {
+				count++
+				if sibling == node {
+					position = count
+				}
+			}
+		}
+	}
+	if count <= 1 {
+		return node.Data
+	}
+	return fmt.Sprintf("%s[%d]", node.Data, position)
+}
+
+func ancestorID(node *html.Node) string {
+	for n := node.Parent; n != nil; n = n.Parent {
+		if id := htmlquery.SelectAttr(n, "id"); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ownText returns the text directly inside node, leaving out its child elements.
+func ownText(node *html.Node) string {
+	var buf bytes.Buffer
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.TextNode {
+			buf.WriteString(child.Data)
+		}
+	}
+	return buf.String()
+}
+
+// xpathLiteral quotes s as an XPath string literal, which can't hold both kinds of quotes.
+func xpathLiteral(s string) (string, bool) {
+	switch {
+	case !strings.Contains(s, "'"):
+		return "'" + s + "'", true
+	case !strings.Contains(s, `"`):
+		return `"` + s + `"`, true
+	}
+	return "", false
+}
@@ -0,0 +1,117 @@
+package suggest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+)
+
+// jsonKeyRe matches the object keys a dotted JSONPath can address.
+var jsonKeyRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// jsonNameKeys are the keys that name the product in an array of items.
+var jsonNameKeys = []string{"name", "title", "productName", "product_name"}
+
+// jsonPath is a JSONPath to a value holding the price.
+type jsonPath struct {
+	path string
+	// priceKey is set when the value's key says it is a price, like "price" or "amount".
+	priceKey bool
+	// byName is set when the path picks an array item by its product name rather than its position.
+	byName bool
+}
+
+func (s *suggester) suggestJSON(data any) {
+	for _, p := range s.jsonPaths(data) {
+		s.add(link.LinkTypeJSON, p.path, p.score(70), p.note())
+	}
+}
+
+func (p jsonPath) score(base int) int {
+	if p.priceKey {
+		base += 10
+	}
+	if p.byName {
+		base += 5
+	}
+	return base
+}
+
+func (p jsonPath) note() string {
+	if p.byName {
+		return "picks the item by product name"
+	}
+	return ""
+}
+
+// jsonPaths lists the paths to the scalar values in data that hold the target price.
+func (s *suggester) jsonPaths(data any) []jsonPath {
+	var paths []jsonPath
+	s.walkJSON(data, "$", "", false, &paths)
+	return paths
+}
+
+func (s *suggester) walkJSON(value any, path, key string, byName bool, paths *[]jsonPath) {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if jsonKeyRe.MatchString(k) {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			s.walkJSON(v[k], path+"."+k, k, byName, paths)
+		}
+	case []any:
+		for i, item := range v {
+			if filter, ok := s.nameFilter(item); ok {
+				s.walkJSON(item, path+filter, key, true, paths)
+			}
+			s.walkJSON(item, fmt.Sprintf("%s[%d]", path, i), key, byName, paths)
+		}
+	case string:
+		// Long strings are descriptions that merely mention the price.
+		if len(v) <= 32 && s.matches(v) {
+			*paths = append(*paths, jsonPath{path: path, priceKey: isPriceKey(key), byName: byName})
+		}
+	case json.Number:
+		if s.matches(v.String()) {
+			*paths = append(*paths, jsonPath{path: path, priceKey: isPriceKey(key), byName: byName})
+		}
+	}
+}
+
+// nameFilter returns a filter picking item out of its array by name, when it is the product being looked for.
+func (s *suggester) nameFilter(item any) (string, bool) {
+	productName := strings.ToLower(strings.TrimSpace(s.opts.ProductName))
+	obj, ok := item.(map[string]any)
+	if productName == "" || !ok {
+		return "", false
+	}
+
+	for _, key := range jsonNameKeys {
+		name, ok := obj[key].(string)
+		if !ok || !strings.Contains(strings.ToLower(name), productName) || strings.ContainsAny(name, `'()[]`) {
+			continue
+		}
+		return fmt.Sprintf("[?(@.%s == '%s')]", key, name), true
+	}
+
+	return "", false
+}
+
+func isPriceKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"price", "amount", "cost", "value"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package suggest
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/antchfx/htmlquery"
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller/parsers"
+	"github.com/turbak/bigmacindex/internal/poller/pricetext"
+)
+
+// maxSuggestions caps how many selectors are proposed for a page.
+const maxSuggestions = 10
+
+// Suggestion is a selector that reads the known price from the page.
+type Suggestion struct {
+	LinkType link.LinkType
+	Selector string
+	// Sample is what the selector extracts from the page.
+	Sample string
+	// Score ranks suggestions by how likely they are to survive changes to the page; higher is better.
+	Score int
+	Note  string
+}
+
+// Options describe the price a human sees on the page.
+type Options struct {
+	// Price is the price as seen, e.g. "199" or "5,69 €".
+	Price string
+	// ProductName, when given, is used to prefer selectors that pick the product by name.
+	ProductName string
+	Number      pricetext.Options
+}
+
+// Suggest searches a UTF-8 document for text nodes, attributes, JSON values and structured data
+// holding opts.Price and returns selectors reading it, best first. Every suggestion is checked
+// against the document with the parser of its link type, so it extracts the price as it is now.
+func Suggest(body []byte, opts Options) ([]Suggestion, error) {
+	target, err := pricetext.Parse(opts.Price, opts.Number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price: %w", err)
+	}
+
+	s := suggester{body: body, target: target, opts: opts, seen: make(map[string]bool)}
+
+	var data any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&data) == nil {
+		s.suggestJSON(data)
+	} else {
+		doc, err := htmlquery.Parse(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse page: %w", err)
+		}
+		s.suggestStructured()
+		s.suggestHTML(doc)
+		s.suggestEmbeddedJSON(doc)
+	}
+
+	slices.SortStableFunc(s.suggestions, func(a, b Suggestion) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(len(a.Selector), len(b.Selector))
+	})
+	if len(s.suggestions) > maxSuggestions {
+		s.suggestions = s.suggestions[:maxSuggestions]
+	}
+
+	return s.suggestions, nil
+}
+
+type suggester struct {
+	body        []byte
+	target      int64
+	opts        Options
+	seen        map[string]bool
+	suggestions []Suggestion
+}
+
+type parser interface {
+	ParsePriceStringFromReader(reader io.Reader, priceSelector string) (string, error)
+}
+
+var parsersByType = map[link.LinkType]parser{
+	link.LinkTypeHTML:         parsers.HTMLParser{},
+	link.LinkTypeJSON:         parsers.JSONParser{},
+	link.LinkTypeCSS:          parsers.CSSParser{},
+	link.LinkTypeStructured:   parsers.StructuredDataParser{},
+	link.LinkTypeEmbeddedJSON: parsers.EmbeddedJSONParser{},
+}
+
+// add verifies that selector reads the target price from the page before suggesting it.
+func (s *suggester) add(linkType link.LinkType, selector string, score int, note string) bool {
+	key := string(linkType) + "\x00" + selector
+	if s.seen[key] {
+		return false
+	}
+	s.seen[key] = true
+
+	sample, ok := s.verify(linkType, selector)
+	if !ok {
+		return false
+	}
+
+	s.suggestions = append(s.suggestions, Suggestion{
+		LinkType: linkType,
+		Selector: selector,
+		Sample:   strings.TrimSpace(sample),
+		Score:    score,
+		Note:     note,
+	})
+	return true
+}
+
+func (s *suggester) verify(linkType link.LinkType, selector string) (string, bool) {
+	sample, err := parsersByType[linkType].ParsePriceStringFromReader(bytes.NewReader(s.body), selector)
+	if err != nil {
+		return "", false
+	}
+
+	return sample, s.matches(sample)
+}
+
+func (s *suggester) matches(text string) bool {
+	amount, err := pricetext.Parse(text, s.opts.Number)
+	return err == nil && amount == s.target
+}
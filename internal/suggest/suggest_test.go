@@ -0,0 +1,139 @@
+package suggest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/turbak/bigmacindex/internal/domain/link"
+	"github.com/turbak/bigmacindex/internal/poller/pricetext"
+)
+
+func TestSuggest(t *testing.T) {
+	var (
+		usd = pricetext.Options{MinorUnits: 2, Locale: pricetext.LocaleForCountry("US")}
+		rub = pricetext.Options{MinorUnits: 2, Locale: pricetext.LocaleForCountry("RU")}
+	)
+
+	tests := []struct {
+		name         string
+		body         string
+		opts         Options
+		wantType     link.LinkType
+		wantSelector string
+		// wantAlso are other selectors that must be among the suggestions.
+		wantAlso []string
+	}{
+		{
+			name:         "JSON item picked by product name",
+			body:         `{"menu": {"items": [{"name": "McChicken", "price": 4.19}, {"name": "Big Mac", "price": 5.69}]}}`,
+			opts:         Options{Price: "$5.69", ProductName: "Big Mac", Number: usd},
+			wantType:     link.LinkTypeJSON,
+			wantSelector: "$.menu.items[?(@.name == 'Big Mac')].price",
+			wantAlso:     []string{"$.menu.items[1].price"},
+		},
+		{
+			name:         "JSON price key",
+			body:         `{"data": {"product": {"amount": "5.69", "id": 569}}}`,
+			opts:         Options{Price: "5.69", Number: usd},
+			wantType:     link.LinkTypeJSON,
+			wantSelector: "$.data.product.amount",
+		},
+		{
+			name: "HTML price class",
+			body: `<html><body><div id="menu">
+				<div class="item"><h2>Big Mac</h2><span class="price">249 ₽</span></div>
+				<div class="item"><h2>Fries</h2><span class="price">99 ₽</span></div>
+			</div></body></html>`,
+			opts:         Options{Price: "249", Number: rub},
+			wantType:     link.LinkTypeCSS,
+			wantSelector: "span.price",
+			wantAlso:     []string{"//span[@class='price']"},
+		},
+		{
+			name: "structured data wins",
+			body: `<html><head><script type="application/ld+json">
+				{"@type": "Product", "name": "Big Mac", "offers": {"@type": "Offer", "price": "5.69", "priceCurrency": "USD"}}
+			</script></head><body><p>Price: <b>$5.69</b></p></body></html>`,
+			opts:         Options{Price: "5.69", ProductName: "Big Mac", Number: usd},
+			wantType:     link.LinkTypeStructured,
+			wantSelector: "Big Mac",
+			wantAlso:     []string{"/html/body/p/b"},
+		},
+		{
+			name: "embedded JSON",
+			body: `<html><head><script id="__NEXT_DATA__" type="application/json">{"props": {"product": {"display": "5.69"}}}</script></head>
+				<body><span data-price="5.69">5,69</span></body></html>`,
+			opts:         Options{Price: "5.69", Number: usd},
+			wantType:     link.LinkTypeEmbeddedJSON,
+			wantSelector: "script#__NEXT_DATA__ | $.props.product.display",
+			wantAlso:     []string{"/html/body/span/@data-price"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions, err := Suggest([]byte(tt.body), tt.opts)
+			if err != nil {
+				t.Fatalf("Suggest returned error: %v", err)
+			}
+			if len(suggestions) == 0 {
+				t.Fatal("Suggest returned no suggestions")
+			}
+
+			if best := suggestions[0]; best.LinkType != tt.wantType || best.Selector != tt.wantSelector {
+				t.Errorf("best suggestion = %s %q, want %s %q", best.LinkType, best.Selector, tt.wantType, tt.wantSelector)
+			}
+
+			selectors := make(map[string]bool)
+			for _, s := range suggestions {
+				selectors[s.Selector] = true
+				sample, err := parsersByType[s.LinkType].ParsePriceStringFromReader(bytes.NewReader([]byte(tt.body)), s.Selector)
+				if err != nil {
+					t.Errorf("suggested %s %q fails to parse: %v", s.LinkType, s.Selector, err)
+					continue
+				}
+				amount, err := pricetext.Parse(sample, tt.opts.Number)
+				want, _ := pricetext.Parse(tt.opts.Price, tt.opts.Number)
+				if err != nil || amount != want {
+					t.Errorf("suggested %s %q reads %q, not the price %q", s.LinkType, s.Selector, sample, tt.opts.Price)
+				}
+			}
+			for _, selector := range tt.wantAlso {
+				if !selectors[selector] {
+					t.Errorf("suggestions %v lack %q", selectors, selector)
+				}
+			}
+		})
+	}
+}
+
+func TestSuggestNothing(t *testing.T) {
+	opts := Options{Price: "5.69", Number: pricetext.Options{MinorUnits: 2}}
+	if suggestions, err := Suggest([]byte(`<html><body><p>Fries 1.00</p></body></html>`), opts); err != nil || len(suggestions) != 0 {
+		t.Errorf("Suggest = %v, %v, want no suggestions", suggestions, err)
+	}
+
+	if _, err := Suggest([]byte(`{"price": 5}`), Options{Price: "free"}); err == nil {
+		t.Error("Suggest accepted a price without a number")
+	}
+}
+
+func TestSuggestCapsSuggestions(t *testing.T) {
+	var body bytes.Buffer
+	body.WriteString(`{"items": [`)
+	for i := range 20 {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		body.WriteString(`{"price": 5.69}`)
+	}
+	body.WriteString(`]}`)
+
+	suggestions, err := Suggest(body.Bytes(), Options{Price: "5.69", Number: pricetext.Options{MinorUnits: 2}})
+	if err != nil {
+		t.Fatalf("Suggest returned error: %v", err)
+	}
+	if len(suggestions) != maxSuggestions {
+		t.Errorf("Suggest returned %d suggestions, want %d", len(suggestions), maxSuggestions)
+	}
+}
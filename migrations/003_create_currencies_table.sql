@@ -0,0 +1,154 @@
+-- +goose Up
+CREATE TABLE currencies (
+                            code TEXT PRIMARY KEY,
+                            name TEXT NOT NULL,
+                            minor_units INTEGER NOT NULL
+);
+
+CREATE TABLE currency_symbols (
+                                  currency_code TEXT NOT NULL REFERENCES currencies(code) ON DELETE CASCADE,
+                                  symbol TEXT NOT NULL,
+                                  PRIMARY KEY (currency_code, symbol)
+);
+
+INSERT INTO currencies (code, name, minor_units) VALUES
+('AED', 'UAE Dirham', 2),
+('ARS', 'Argentine Peso', 2),
+('AUD', 'Australian Dollar', 2),
+('AZN', 'Azerbaijan Manat', 2),
+('BHD', 'Bahraini Dinar', 3),
+('BRL', 'Brazilian Real', 2),
+('CAD', 'Canadian Dollar', 2),
+('CHF', 'Swiss Franc', 2),
+('CLP', 'Chilean Peso', 0),
+('CNY', 'Yuan Renminbi', 2),
+('COP', 'Colombian Peso', 2),
+('CRC', 'Costa Rican Colon', 2),
+('CZK', 'Czech Koruna', 2),
+('DKK', 'Danish Krone', 2),
+('EGP', 'Egyptian Pound', 2),
+('EUR', 'Euro', 2),
+('GBP', 'Pound Sterling', 2),
+('GTQ', 'Quetzal', 2),
+('HKD', 'Hong Kong Dollar', 2),
+('HNL', 'Lempira', 2),
+('HUF', 'Forint', 2),
+('IDR', 'Rupiah', 2),
+('ILS', 'New Israeli Sheqel', 2),
+('INR', 'Indian Rupee', 2),
+('JOD', 'Jordanian Dinar', 3),
+('JPY', 'Yen', 0),
+('KRW', 'Won', 0),
+('KWD', 'Kuwaiti Dinar', 3),
+('KZT', 'Tenge', 2),
+('LKR', 'Sri Lanka Rupee', 2),
+('MDL', 'Moldovan Leu', 2),
+('MXN', 'Mexican Peso', 2),
+('MYR', 'Malaysian Ringgit', 2),
+('NIO', 'Cordoba Oro', 2),
+('NOK', 'Norwegian Krone', 2),
+('NZD', 'New Zealand Dollar', 2),
+('OMR', 'Rial Omani', 3),
+('PEN', 'Sol', 2),
+('PHP', 'Philippine Peso', 2),
+('PKR', 'Pakistan Rupee', 2),
+('PLN', 'Zloty', 2),
+('QAR', 'Qatari Rial', 2),
+('RON', 'Romanian Leu', 2),
+('RUB', 'Russian Ruble', 2),
+('SAR', 'Saudi Riyal', 2),
+('SEK', 'Swedish Krona', 2),
+('SGD', 'Singapore Dollar', 2),
+('THB', 'Baht', 2),
+('TRY', 'Turkish Lira', 2),
+('TWD', 'New Taiwan Dollar', 2),
+('UAH', 'Hryvnia', 2),
+('USD', 'US Dollar', 2),
+('UYU', 'Peso Uruguayo', 2),
+('VND', 'Dong', 0),
+('ZAR', 'Rand', 2);
+
+INSERT INTO currency_symbols (currency_code, symbol) VALUES
+('AED', 'د.إ'),
+('ARS', '$'),
+('AUD', '$'),
+('AUD', 'A$'),
+('AZN', '₼'),
+('BHD', '.د.ب'),
+('BRL', 'R$'),
+('CAD', '$'),
+('CAD', 'C$'),
+('CAD', 'CA$'),
+('CHF', 'Fr.'),
+('CLP', '$'),
+('CNY', '¥'),
+('CNY', '元'),
+('CNY', 'RMB'),
+('COP', '$'),
+('CRC', '₡'),
+('CZK', 'Kč'),
+('DKK', 'kr.'),
+('DKK', 'kr'),
+('EGP', 'E£'),
+('EGP', 'ج.م'),
+('EUR', '€'),
+('GBP', '£'),
+('GTQ', 'Q'),
+('HKD', '$'),
+('HKD', 'HK$'),
+('HNL', 'L'),
+('HUF', 'Ft'),
+('IDR', 'Rp'),
+('ILS', '₪'),
+('INR', '₹'),
+('INR', 'Rs.'),
+('JOD', 'د.ا'),
+('JPY', '¥'),
+('JPY', '円'),
+('KRW', '₩'),
+('KRW', '원'),
+('KWD', 'د.ك'),
+('KZT', '₸'),
+('LKR', 'Rs.'),
+('LKR', 'රු'),
+('MDL', 'lei'),
+('MXN', '$'),
+('MXN', 'MX$'),
+('MYR', 'RM'),
+('NIO', 'C$'),
+('NOK', 'kr'),
+('NZD', '$'),
+('NZD', 'NZ$'),
+('OMR', 'ر.ع.'),
+('PEN', 'S/'),
+('PHP', '₱'),
+('PKR', '₨'),
+('PKR', 'Rs.'),
+('PLN', 'zł'),
+('QAR', 'ر.ق'),
+('RON', 'lei'),
+('RUB', '₽'),
+('RUB', 'руб.'),
+('RUB', 'руб'),
+('RUB', 'р.'),
+('SAR', 'ر.س'),
+('SEK', 'kr'),
+('SGD', '$'),
+('SGD', 'S$'),
+('THB', '฿'),
+('TRY', '₺'),
+('TRY', 'TL'),
+('TWD', 'NT$'),
+('TWD', '$'),
+('UAH', '₴'),
+('UAH', 'грн'),
+('USD', '$'),
+('USD', 'US$'),
+('UYU', '$U'),
+('UYU', '$'),
+('VND', '₫'),
+('ZAR', 'R');
+
+-- +goose Down
+DROP TABLE IF EXISTS currency_symbols;
+DROP TABLE IF EXISTS currencies;
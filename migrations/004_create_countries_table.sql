@@ -0,0 +1,76 @@
+-- +goose Up
+CREATE TABLE countries (
+                           code TEXT PRIMARY KEY,
+                           name TEXT NOT NULL,
+                           currency_code TEXT NOT NULL REFERENCES currencies(code)
+);
+
+INSERT INTO countries (code, name, currency_code) VALUES
+('AE', 'United Arab Emirates', 'AED'),
+('AR', 'Argentina', 'ARS'),
+('AT', 'Austria', 'EUR'),
+('AU', 'Australia', 'AUD'),
+('AZ', 'Azerbaijan', 'AZN'),
+('BE', 'Belgium', 'EUR'),
+('BH', 'Bahrain', 'BHD'),
+('BR', 'Brazil', 'BRL'),
+('CA', 'Canada', 'CAD'),
+('CH', 'Switzerland', 'CHF'),
+('CL', 'Chile', 'CLP'),
+('CN', 'China', 'CNY'),
+('CO', 'Colombia', 'COP'),
+('CR', 'Costa Rica', 'CRC'),
+('CZ', 'Czechia', 'CZK'),
+('DE', 'Germany', 'EUR'),
+('DK', 'Denmark', 'DKK'),
+('EG', 'Egypt', 'EGP'),
+('ES', 'Spain', 'EUR'),
+('FI', 'Finland', 'EUR'),
+('FR', 'France', 'EUR'),
+('GB', 'United Kingdom', 'GBP'),
+('GR', 'Greece', 'EUR'),
+('GT', 'Guatemala', 'GTQ'),
+('HK', 'Hong Kong', 'HKD'),
+('HN', 'Honduras', 'HNL'),
+('HU', 'Hungary', 'HUF'),
+('ID', 'Indonesia', 'IDR'),
+('IE', 'Ireland', 'EUR'),
+('IL', 'Israel', 'ILS'),
+('IN', 'India', 'INR'),
+('IT', 'Italy', 'EUR'),
+('JO', 'Jordan', 'JOD'),
+('JP', 'Japan', 'JPY'),
+('KR', 'South Korea', 'KRW'),
+('KW', 'Kuwait', 'KWD'),
+('KZ', 'Kazakhstan', 'KZT'),
+('LK', 'Sri Lanka', 'LKR'),
+('MD', 'Moldova', 'MDL'),
+('MX', 'Mexico', 'MXN'),
+('MY', 'Malaysia', 'MYR'),
+('NI', 'Nicaragua', 'NIO'),
+('NL', 'Netherlands', 'EUR'),
+('NO', 'Norway', 'NOK'),
+('NZ', 'New Zealand', 'NZD'),
+('OM', 'Oman', 'OMR'),
+('PE', 'Peru', 'PEN'),
+('PH', 'Philippines', 'PHP'),
+('PK', 'Pakistan', 'PKR'),
+('PL', 'Poland', 'PLN'),
+('PT', 'Portugal', 'EUR'),
+('QA', 'Qatar', 'QAR'),
+('RO', 'Romania', 'RON'),
+('RU', 'Russia', 'RUB'),
+('SA', 'Saudi Arabia', 'SAR'),
+('SE', 'Sweden', 'SEK'),
+('SG', 'Singapore', 'SGD'),
+('TH', 'Thailand', 'THB'),
+('TR', 'Türkiye', 'TRY'),
+('TW', 'Taiwan', 'TWD'),
+('UA', 'Ukraine', 'UAH'),
+('US', 'United States', 'USD'),
+('UY', 'Uruguay', 'UYU'),
+('VN', 'Vietnam', 'VND'),
+('ZA', 'South Africa', 'ZAR');
+
+-- +goose Down
+DROP TABLE IF EXISTS countries;
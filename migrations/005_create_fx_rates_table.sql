@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE fx_rates (
+                          id INTEGER PRIMARY KEY AUTOINCREMENT,
+                          date TEXT NOT NULL,
+                          base TEXT NOT NULL,
+                          quote TEXT NOT NULL,
+                          rate REAL NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_fx_rates_unique ON fx_rates(date, base, quote);
+
+-- +goose Down
+DROP TABLE IF EXISTS fx_rates;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE poll_runs (
+                           id INTEGER PRIMARY KEY AUTOINCREMENT,
+                           started_at DATETIME NOT NULL,
+                           finished_at DATETIME NOT NULL,
+                           succeeded INTEGER NOT NULL,
+                           failed INTEGER NOT NULL
+);
+
+CREATE TABLE poll_attempts (
+                               id INTEGER PRIMARY KEY AUTOINCREMENT,
+                               run_id INTEGER NOT NULL REFERENCES poll_runs(id) ON DELETE CASCADE,
+                               link_id INTEGER NOT NULL,
+                               product_name TEXT NOT NULL,
+                               country_code TEXT NOT NULL,
+                               url TEXT NOT NULL,
+                               http_status INTEGER NOT NULL,
+                               latency_ms INTEGER NOT NULL,
+                               raw_value TEXT NOT NULL,
+                               parsed_price TEXT NOT NULL,
+                               error_kind TEXT NOT NULL,
+                               error TEXT NOT NULL,
+                               created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_poll_attempts_run ON poll_attempts(run_id);
+CREATE INDEX idx_poll_attempts_link ON poll_attempts(link_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS poll_attempts;
+DROP TABLE IF EXISTS poll_runs;
@@ -0,0 +1,59 @@
+-- +goose Up
+CREATE TABLE prices_new (
+                        id INTEGER PRIMARY KEY AUTOINCREMENT,
+                        product_name TEXT NOT NULL,
+                        amount INTEGER NOT NULL,
+                        minor_units INTEGER NOT NULL,
+                        currency TEXT NOT NULL,
+                        country_code TEXT NOT NULL,
+                        created_date TEXT NOT NULL
+);
+
+-- price_cents holds the fractional part in the currency's minor units; unknown currencies are taken to have 2.
+-- Rows polled before the currency was recorded have none, so theirs is the currency of their country.
+INSERT INTO prices_new (id, product_name, amount, minor_units, currency, country_code, created_date)
+SELECT p.id,
+       p.product_name,
+       CASE COALESCE(c.minor_units, 2)
+           WHEN 0 THEN p.price
+           WHEN 3 THEN p.price * 1000 + p.price_cents
+           ELSE p.price * 100 + p.price_cents
+           END,
+       COALESCE(c.minor_units, 2),
+       COALESCE(NULLIF(p.currency, ''), co.currency_code, ''),
+       p.country_code,
+       p.created_date
+FROM prices p
+         LEFT JOIN countries co ON co.code = p.country_code
+         LEFT JOIN currencies c ON c.code = COALESCE(NULLIF(p.currency, ''), co.currency_code);
+
+DROP TABLE prices;
+ALTER TABLE prices_new RENAME TO prices;
+
+CREATE UNIQUE INDEX idx_prices_unique ON prices(product_name, country_code, created_date);
+
+-- +goose Down
+CREATE TABLE prices_old (
+                        id INTEGER PRIMARY KEY AUTOINCREMENT,
+                        product_name TEXT NOT NULL,
+                        price INTEGER NOT NULL,
+                        price_cents INTEGER NOT NULL,
+                        currency TEXT NOT NULL,
+                        country_code TEXT NOT NULL,
+                        created_date TEXT NOT NULL
+);
+
+INSERT INTO prices_old (id, product_name, price, price_cents, currency, country_code, created_date)
+SELECT id,
+       product_name,
+       CASE minor_units WHEN 0 THEN amount WHEN 3 THEN amount / 1000 ELSE amount / 100 END,
+       CASE minor_units WHEN 0 THEN 0 WHEN 3 THEN amount % 1000 ELSE amount % 100 END,
+       currency,
+       country_code,
+       created_date
+FROM prices;
+
+DROP TABLE prices;
+ALTER TABLE prices_old RENAME TO prices;
+
+CREATE UNIQUE INDEX idx_prices_unique ON prices(product_name, country_code, created_date);
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE links ADD COLUMN steps TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE links DROP COLUMN steps;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE links ADD COLUMN fallbacks TEXT NOT NULL DEFAULT '';
+ALTER TABLE poll_runs ADD COLUMN fallbacks INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE poll_attempts ADD COLUMN matched_rule INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE poll_attempts ADD COLUMN matched_selector TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE poll_attempts DROP COLUMN matched_selector;
+ALTER TABLE poll_attempts DROP COLUMN matched_rule;
+ALTER TABLE poll_runs DROP COLUMN fallbacks;
+ALTER TABLE links DROP COLUMN fallbacks;
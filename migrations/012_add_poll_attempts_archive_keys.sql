@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE poll_attempts ADD COLUMN archive_keys TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE poll_attempts DROP COLUMN archive_keys;
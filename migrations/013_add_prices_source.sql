@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE prices ADD COLUMN source TEXT NOT NULL DEFAULT 'poll';
+ALTER TABLE prices ADD COLUMN source_ref TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE prices DROP COLUMN source_ref;
+ALTER TABLE prices DROP COLUMN source;
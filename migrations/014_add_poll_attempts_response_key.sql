@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE poll_attempts ADD COLUMN response_key TEXT NOT NULL DEFAULT '';
+
+-- A successful attempt's last response is its price page; failed ones may have ended on a step.
+UPDATE poll_attempts SET response_key = substr(archive_keys, -64) WHERE error_kind = '' AND archive_keys != '';
+
+-- +goose Down
+ALTER TABLE poll_attempts DROP COLUMN response_key;